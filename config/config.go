@@ -16,12 +16,17 @@ type (
 		OutboxRelay     OutboxRelay
 		Kafka           Kafka
 		KafkaController KafkaController
+		Processor       Processor
+		Preview         Preview
+		Fetcher         Fetcher
 		Swagger         Swagger
 	}
 
 	HTTP struct {
-		Port           string `env:"HTTP_PORT,required"`
-		UsePreforkMode bool   `env:"HTTP_USE_PREFORK_MODE" envDefault:"false"`
+		Port           string        `env:"HTTP_PORT,required"`
+		UsePreforkMode bool          `env:"HTTP_USE_PREFORK_MODE" envDefault:"false"`
+		BodyLimit      int           `env:"HTTP_BODY_LIMIT" envDefault:"16777216"` // файл до 10 МБ и поля формы; пакет читается потоком и не ограничен им
+		ReadTimeout    time.Duration `env:"HTTP_READ_TIMEOUT" envDefault:"60s"`    // чтение всего запроса, включая потоковое тело пакета
 	}
 
 	Log struct {
@@ -64,6 +69,37 @@ type (
 		ShutdownTimeout time.Duration `env:"KAFKA_CONTROLLER_SHUTDOWN_TIMEOUT" envDefault:"5s"`
 	}
 
+	Processor struct {
+		Background string `env:"PROCESSOR_BACKGROUND" envDefault:"#ffffff"` // фон для прозрачных изображений при сохранении в JPEG
+		Metadata   Metadata
+	}
+
+	// Metadata - EXIF/XMP, которые пишутся во все JPEG и PNG результаты.
+	Metadata struct {
+		Copyright   string            `env:"METADATA_COPYRIGHT"`
+		Artist      string            `env:"METADATA_ARTIST"`
+		Description string            `env:"METADATA_DESCRIPTION"`
+		SourceURL   string            `env:"METADATA_SOURCE_URL"`
+		Custom      map[string]string `env:"METADATA_CUSTOM"` // key1:value1,key2:value2
+	}
+
+	// Preview - синхронная обработка небольших изображений прямо в HTTP-запросе.
+	Preview struct {
+		MaxFileSize int64         `env:"PREVIEW_MAX_FILE_SIZE" envDefault:"2097152"`
+		MaxPixels   int           `env:"PREVIEW_MAX_PIXELS" envDefault:"4000000"`
+		Concurrency int           `env:"PREVIEW_CONCURRENCY" envDefault:"4"`
+		WaitTimeout time.Duration `env:"PREVIEW_WAIT_TIMEOUT" envDefault:"1s"` // ожидание свободного слота
+		Timeout     time.Duration `env:"PREVIEW_TIMEOUT" envDefault:"3s"`      // дедлайн обработки, проверяется между этапами, а не внутри них
+	}
+
+	// Fetcher - загрузка изображений по URL.
+	Fetcher struct {
+		Timeout      time.Duration `env:"FETCH_TIMEOUT" envDefault:"10s"`
+		MaxSize      int64         `env:"FETCH_MAX_SIZE" envDefault:"10485760"`
+		MaxRedirects int           `env:"FETCH_MAX_REDIRECTS" envDefault:"3"`
+		AllowedHosts []string      `env:"FETCH_ALLOWED_HOSTS"` // example.com,*.cdn.example.com; пусто - любой публичный хост
+	}
+
 	Swagger struct {
 		Enabled bool `env:"SWAGGER_ENABLED" envDefault:"false"`
 	}
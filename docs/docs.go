@@ -15,12 +15,163 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/v1/collage": {
+            "post": {
+                "description": "Composes existing images into one grid image. Processing goes through outbox -\u003e kafka -\u003e worker, result is a new image",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Create collage",
+                "parameters": [
+                    {
+                        "description": "Source image IDs and grid layout",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.Collage"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProcessImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Source image not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/compare": {
+            "get": {
+                "description": "Computes SSIM and PSNR between two images(bigger one is resized to the smaller). With diff=true returns PNG heatmap, metrics are in X-SSIM / X-PSNR headers",
+                "produces": [
+                    "application/json",
+                    "image/png"
+                ],
+                "tags": [
+                    "compare"
+                ],
+                "summary": "Compare two images",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First image ID(uuid)",
+                        "name": "a",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Second image ID(uuid)",
+                        "name": "b",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "processed",
+                            "original"
+                        ],
+                        "type": "string",
+                        "description": "Which version of both images to compare(default processed)",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return visual diff heatmap",
+                        "name": "diff",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Comparison"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Image not found or not processed yet",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "Image has too many pixels",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Images can't be decoded",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many inline operations in progress",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Comparison timed out",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/image/{id}": {
             "get": {
-                "description": "Downloads processed image from S3 by key",
+                "description": "Downloads processed image from S3 by key. While the image is still in the queue responds 202 with its status and Location of the status resource",
                 "produces": [
                     "image/jpeg",
-                    "image/png"
+                    "image/png",
+                    "image/gif",
+                    "image/bmp",
+                    "image/tiff",
+                    "image/x-icon"
                 ],
                 "tags": [
                     "images"
@@ -42,6 +193,12 @@ const docTemplate = `{
                             "type": "file"
                         }
                     },
+                    "202": {
+                        "description": "Not processed yet",
+                        "schema": {
+                            "$ref": "#/definitions/response.ImageStatus"
+                        }
+                    },
                     "400": {
                         "description": "Invalid ID",
                         "schema": {
@@ -54,6 +211,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Error"
                         }
                     },
+                    "422": {
+                        "description": "Processing failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal",
                         "schema": {
@@ -102,79 +265,65 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/upload": {
-            "post": {
-                "description": "Uploads image to S3, save metadata to postgres, save metadata to outbox(postgres)",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/v1/image/{id}/compare": {
+            "get": {
+                "description": "Computes SSIM and PSNR between original and processed result(bigger one is resized to the smaller). With diff=true returns PNG heatmap, metrics are in X-SSIM / X-PSNR headers",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "image/png"
                 ],
                 "tags": [
-                    "images"
+                    "compare"
                 ],
-                "summary": "Upload and process image",
+                "summary": "Compare original and processed image",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Image file(jpg, png)",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "enum": [
-                            "resize",
-                            "thumbnail",
-                            "watermark"
-                        ],
                         "type": "string",
-                        "description": "Operation",
-                        "name": "operation",
-                        "in": "formData",
+                        "description": "Image ID(uuid)",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Text(required for watermark operation)",
-                        "name": "text",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Width(required for resize operation)",
-                        "name": "width",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Height(required for resize operation)",
-                        "name": "height",
-                        "in": "formData"
+                        "type": "boolean",
+                        "description": "Return visual diff heatmap",
+                        "name": "diff",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.ProcessImage"
+                            "$ref": "#/definitions/response.Comparison"
                         }
                     },
                     "400": {
-                        "description": "Empty file or wrong parameters",
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Image not found or not processed yet",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
                     "413": {
-                        "description": "File too large",
+                        "description": "Image has too many pixels",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
                     },
-                    "415": {
-                        "description": "Unsupported format",
+                    "422": {
+                        "description": "Images can't be decoded",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many inline operations in progress",
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
@@ -184,44 +333,2344 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Error"
                         }
+                    },
+                    "503": {
+                        "description": "Comparison timed out",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "response.Error": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "invalid request body"
+        },
+        "/v1/image/{id}/files/{name}": {
+            "get": {
+                "description": "Downloads an additional file of the processing result: sprite.json for sprite, PNG icons and site.webmanifest for favicon",
+                "produces": [
+                    "application/json",
+                    "application/manifest+json",
+                    "image/png"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Get result file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "sprite.json",
+                        "description": "File name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or name",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
                 }
             }
         },
-        "response.ProcessImage": {
-            "type": "object",
-            "properties": {
-                "content_type": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "image_id": {
-                    "type": "string"
-                },
-                "operation": {
-                    "type": "string"
-                },
-                "original_name": {
-                    "type": "string"
-                },
-                "size": {
-                    "type": "integer"
-                },
-                "status": {
-                    "type": "string"
+        "/v1/image/{id}/original": {
+            "get": {
+                "description": "Downloads the uploaded original from S3 regardless of processing status. Images assembled from other images(collage, sprite, template) have no original",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/bmp",
+                    "image/tiff",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Get original image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Image or original not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/image/{id}/process": {
+            "post": {
+                "description": "Queues a new operation for the already uploaded original without re-uploading it. The result replaces the current processed image. Accepts the same operation fields as /upload",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Reprocess image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "resize",
+                            "thumbnail",
+                            "watermark",
+                            "quantize",
+                            "auto_enhance",
+                            "trim",
+                            "invisible_watermark",
+                            "tiles",
+                            "favicon",
+                            "remove_background",
+                            "transform",
+                            "annotate"
+                        ],
+                        "type": "string",
+                        "description": "Operation",
+                        "name": "operation",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text(required for watermark operation)",
+                        "name": "text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width(required for resize operation, output width for transform)",
+                        "name": "width",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Height(required for resize operation, output height for transform)",
+                        "name": "height",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProcessImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or wrong parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Image not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Image is already being processed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Image has no original(collage, sprite, template)",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/image/{id}/status": {
+            "get": {
+                "description": "Returns image metadata: status, operation, timestamps, whether a processed result exists and why processing failed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Get image status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.ImageStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Image not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/image/{id}/tiles/{path}": {
+            "get": {
+                "description": "Serves files of the pyramid built by tiles operation: descriptor(image.dzi or tiles.json, also returned for empty path), DZI tiles image_files/{level}/{col}_{row}.{format} or XYZ tiles {z}/{x}/{y}.{format}",
+                "produces": [
+                    "application/xml",
+                    "application/json",
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "tiles"
+                ],
+                "summary": "Get tile pyramid file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File path inside the pyramid",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or path",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Image has no tiles or tile not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/image/{id}/versions": {
+            "get": {
+                "description": "Lists processing results of the image, newest first. Every processing stores a new immutable version",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "versions"
+                ],
+                "summary": "List image versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.ImageVersions"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Image not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/image/{id}/versions/{version}": {
+            "get": {
+                "description": "Downloads the processed image of the given version",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/bmp",
+                    "image/tiff",
+                    "image/x-icon"
+                ],
+                "tags": [
+                    "versions"
+                ],
+                "summary": "Get image version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version number",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or version",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Version not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/image/{id}/versions/{version}/current": {
+            "post": {
+                "description": "Makes the given version the processed image returned by GET /v1/image/{id}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "versions"
+                ],
+                "summary": "Set current version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version number",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.ImageVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or version",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Version not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Image is being processed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/images": {
+            "get": {
+                "description": "Lists images, newest first, with cursor pagination. Pass next_cursor from the previous page as cursor. Quality filters apply to images whose original was assessed during processing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "List images",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "processed",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Original content type, e.g. image/png",
+                        "name": "content_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Original file name prefix(case-sensitive)",
+                        "name": "name_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum original size in bytes",
+                        "name": "min_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum original size in bytes",
+                        "name": "max_size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "good",
+                            "fair",
+                            "poor"
+                        ],
+                        "type": "string",
+                        "description": "Quality grade",
+                        "name": "quality_grade",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "blurry",
+                            "overexposed",
+                            "underexposed",
+                            "low_resolution"
+                        ],
+                        "type": "string",
+                        "description": "Quality issue",
+                        "name": "quality_issue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum sharpness(variance of Laplacian)",
+                        "name": "min_sharpness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum sharpness(variance of Laplacian)",
+                        "name": "max_sharpness",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page cursor(next_cursor of the previous page)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size(1-200, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.ImageList"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/images/delete": {
+            "post": {
+                "description": "Deletes many images. Every ID is deleted on its own: results report success or the error of every ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Batch delete",
+                "parameters": [
+                    {
+                        "description": "Image IDs, up to 1000",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.BatchDelete"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.BatchDelete"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body, no IDs or too many IDs",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/preview": {
+            "post": {
+                "description": "Runs the operation inline on a small image and returns the result. Nothing is stored. Accepts the same operation fields as /upload except tiles and favicon",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/bmp",
+                    "image/tiff"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Preview operation",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image file(jpg, png, gif, bmp, tiff), limited by PREVIEW_MAX_FILE_SIZE and PREVIEW_MAX_PIXELS",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "resize",
+                            "thumbnail",
+                            "watermark",
+                            "quantize",
+                            "auto_enhance",
+                            "trim",
+                            "invisible_watermark",
+                            "remove_background",
+                            "transform",
+                            "annotate"
+                        ],
+                        "type": "string",
+                        "description": "Operation",
+                        "name": "operation",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text(required for watermark operation)",
+                        "name": "text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width(required for resize operation, output width for transform)",
+                        "name": "width",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Height(required for resize operation, output height for transform)",
+                        "name": "height",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Empty file or wrong parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "File or output too large",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Image can't be processed",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many previews in progress",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Processing timed out",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/sprite": {
+            "post": {
+                "description": "Packs existing images into one PNG sprite sheet. Frame coordinates are stored in sprite.json manifest, available at /v1/image/{id}/files/sprite.json after processing",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Create sprite sheet",
+                "parameters": [
+                    {
+                        "description": "Source image IDs and packing options",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.Sprite"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProcessImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Source image not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/template/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Get template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the template entirely",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Update template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.Template"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Delete template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Deleted"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Template not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/template/{id}/render": {
+            "post": {
+                "description": "Renders the template with supplied slot images and texts. Processing goes through outbox -\u003e kafka -\u003e worker, result is a new image",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Render template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID(uuid)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Slot images and texts",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.RenderTemplate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProcessImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Template or image not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/templates": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "List templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/response.Template"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Stores a card template: canvas and layers drawn in order. base layers are fixed images, slot layers get images and text layers get texts at render time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Create template",
+                "parameters": [
+                    {
+                        "description": "Template",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.Template"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/upload": {
+            "post": {
+                "description": "Uploads image to S3, save metadata to postgres, save metadata to outbox(postgres)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Upload and process image",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image file(jpg, png, gif, bmp, tiff, svg)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "resize",
+                            "thumbnail",
+                            "watermark",
+                            "quantize",
+                            "auto_enhance",
+                            "trim",
+                            "invisible_watermark",
+                            "tiles",
+                            "favicon",
+                            "remove_background",
+                            "transform",
+                            "annotate"
+                        ],
+                        "type": "string",
+                        "description": "Operation",
+                        "name": "operation",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text(required for watermark operation)",
+                        "name": "text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width(required for resize operation, output width for transform)",
+                        "name": "width",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Height(required for resize operation, output height for transform)",
+                        "name": "height",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Palette size for quantize(2-256, default 64)",
+                        "name": "colors",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "median_cut",
+                            "kmeans"
+                        ],
+                        "type": "string",
+                        "description": "Quantize algorithm",
+                        "name": "algorithm",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Floyd-Steinberg dithering for quantize",
+                        "name": "dither",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "png",
+                            "gif"
+                        ],
+                        "type": "string",
+                        "description": "Quantize output format",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Run auto_enhance before the operation",
+                        "name": "enhance",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Histogram equalization for auto_enhance",
+                        "name": "equalize",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Equalization strength for auto_enhance(0-1, default 0.5)",
+                        "name": "strength",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Gray-world white balance for auto_enhance(default true)",
+                        "name": "white_balance",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Invisible watermark payload: uuid or string up to 16 bytes(default image ID)",
+                        "name": "payload",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "dzi",
+                            "xyz"
+                        ],
+                        "type": "string",
+                        "description": "Tile pyramid layout(default dzi)",
+                        "name": "layout",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tile side in pixels(64-1024, default 256)",
+                        "name": "tile_size",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tile overlap for dzi(0-8, default 1)",
+                        "name": "overlap",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "jpeg",
+                            "png"
+                        ],
+                        "type": "string",
+                        "format": "default jpeg",
+                        "description": "Tile format(default jpeg)",
+                        "name": "tile_format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Color tolerance for trim(0-255, default 10) and remove_background(default 30)",
+                        "name": "tolerance",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Background color #RRGGBB for remove_background(default detected from borders)",
+                        "name": "key_color",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Soft edge width in pixels for remove_background(0-20, default 2)",
+                        "name": "feather",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "remove_background keys only the area connected to borders(default true)",
+                        "name": "contiguous",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Border pixels kept after trim(default 0)",
+                        "name": "padding",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Affine matrix a,b,c,d,e,f for transform: x'=a*x+b*y+c, y'=d*x+e*y+f",
+                        "name": "matrix",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Source corners x,y for perspective transform: top-left, top-right, bottom-right, bottom-left",
+                        "name": "corners",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "bilinear",
+                            "bicubic"
+                        ],
+                        "type": "string",
+                        "description": "Transform sampling(default bilinear)",
+                        "name": "interpolation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Transform fill color #RRGGBB or #RRGGBBAA(default transparent)",
+                        "name": "fill",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Annotate primitives as JSON array: [{\\",
+                        "name": "shapes",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Copyright written to EXIF/XMP(overrides service default)",
+                        "name": "copyright",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Artist written to EXIF/XMP(overrides service default)",
+                        "name": "artist",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Description written to EXIF/XMP(overrides service default)",
+                        "name": "description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Source URL written to XMP dc:source(overrides service default)",
+                        "name": "source_url",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom XMP fields as JSON object, e.g. {\\",
+                        "name": "custom_metadata",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "SVG rasterization width(keeps aspect ratio if only one side is set)",
+                        "name": "svg_width",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "SVG rasterization height",
+                        "name": "svg_height",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "SVG rasterization DPI when no size is set(default 96)",
+                        "name": "dpi",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProcessImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Empty file or wrong parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "File or transform output too large",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported format or content doesn't match declared type/extension",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/upload/batch": {
+            "post": {
+                "description": "Uploads many files in one request. Operation fields of the form apply to every file, operations overrides them per file. The body is read as a stream, part by part: form fields must precede the files, every file is validated and queued as soon as it is read. Results report success or the error of every file. If the batch is cut short (malformed body, field after a file, more files than allowed) the files read so far keep their results and error tells why the rest was not read",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Batch upload",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image files(jpg, png, gif, bmp, tiff, svg), up to 100, 10MB each",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation for all files(same values and fields as /upload)",
+                        "name": "operation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Per-file fields as JSON array in the order of files, e.g. [{\\",
+                        "name": "operations",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.BatchUpload"
+                        }
+                    },
+                    "400": {
+                        "description": "No files, malformed form or operations",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "415": {
+                        "description": "Compressed body",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/upload/url": {
+            "post": {
+                "description": "Downloads image from the URL and processes it like /upload. Only public http(s) addresses are fetched: loopback, private, link-local and other internal networks are rejected on every connection, including redirects",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Upload image by URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image URL(http or https)",
+                        "name": "url",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation(same values and fields as /upload)",
+                        "name": "operation",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProcessImage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or forbidden URL, empty file or wrong parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "File or transform output too large",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "502": {
+                        "description": "Remote server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "504": {
+                        "description": "Remote server timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watermark/detect": {
+            "post": {
+                "description": "Extracts the payload embedded by invisible_watermark operation. Survives moderate JPEG recompression and resizing, not cropping",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "watermark"
+                ],
+                "summary": "Detect invisible watermark",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image file(jpg, png, gif, bmp, tiff)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.WatermarkDetection"
+                        }
+                    },
+                    "400": {
+                        "description": "Empty file",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large or has too many pixels",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported format",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Image can't be decoded",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many inline operations in progress",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Detection timed out",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "entity.Metadata": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "description": "дополнительные файлы результата (манифест спрайта, иконки)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "quality": {
+                    "description": "оценка качества оригинала",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Quality"
+                        }
+                    ]
+                },
+                "tiles": {
+                    "description": "пирамида тайлов, построенная операцией tiles",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Tiles"
+                        }
+                    ]
+                },
+                "trim": {
+                    "description": "область, оставленная операцией trim",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.Rect"
+                        }
+                    ]
+                }
+            }
+        },
+        "entity.Quality": {
+            "type": "object",
+            "properties": {
+                "brightness": {
+                    "description": "средняя яркость, 0..255",
+                    "type": "number"
+                },
+                "clipped_highlights": {
+                    "description": "доля пикселей в белом",
+                    "type": "number"
+                },
+                "clipped_shadows": {
+                    "description": "доля пикселей в черном",
+                    "type": "number"
+                },
+                "contrast": {
+                    "description": "стандартное отклонение яркости",
+                    "type": "number"
+                },
+                "grade": {
+                    "description": "good, fair, poor",
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sharpness": {
+                    "description": "дисперсия лапласиана яркости",
+                    "type": "number"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.Rect": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer"
+                },
+                "x": {
+                    "type": "integer"
+                },
+                "y": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.TemplateLayer": {
+            "type": "object",
+            "properties": {
+                "align": {
+                    "description": "left, center, right",
+                    "type": "string"
+                },
+                "color": {
+                    "type": "string"
+                },
+                "fit": {
+                    "description": "base, slot: fill, fit, stretch",
+                    "type": "string"
+                },
+                "font": {
+                    "description": "regular, bold, italic, mono",
+                    "type": "string"
+                },
+                "font_size": {
+                    "type": "number"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "image_id": {
+                    "description": "base",
+                    "type": "string"
+                },
+                "line_height": {
+                    "description": "множитель кегля",
+                    "type": "number"
+                },
+                "max_lines": {
+                    "description": "0 - сколько поместится по высоте",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "slot, text: по имени передаются значения при рендере",
+                    "type": "string"
+                },
+                "text": {
+                    "description": "текст по умолчанию",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "base, slot, text",
+                    "type": "string"
+                },
+                "valign": {
+                    "description": "top, middle, bottom",
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                },
+                "x": {
+                    "type": "integer"
+                },
+                "y": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.Tiles": {
+            "type": "object",
+            "properties": {
+                "descriptor": {
+                    "description": "имя файла дескриптора внутри префикса",
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "layout": {
+                    "type": "string"
+                },
+                "levels": {
+                    "type": "integer"
+                },
+                "overlap": {
+                    "type": "integer"
+                },
+                "tile_size": {
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "request.BatchDelete": {
+            "type": "object",
+            "properties": {
+                "image_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+                        "9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d"
+                    ]
+                }
+            }
+        },
+        "request.Collage": {
+            "type": "object",
+            "properties": {
+                "background": {
+                    "type": "string",
+                    "example": "#ffffff"
+                },
+                "cell_height": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "cell_width": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "columns": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "fit": {
+                    "type": "string",
+                    "enum": [
+                        "fill",
+                        "fit",
+                        "stretch"
+                    ],
+                    "example": "fill"
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "jpeg",
+                        "png"
+                    ],
+                    "example": "jpeg"
+                },
+                "gap": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "image_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+                        "9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d"
+                    ]
+                },
+                "rows": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "request.RenderTemplate": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "jpeg",
+                        "png"
+                    ],
+                    "example": "png"
+                },
+                "images": {
+                    "description": "имя слота -\u003e id изображения",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "texts": {
+                    "description": "имя текстового слоя -\u003e текст",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "request.Sprite": {
+            "type": "object",
+            "properties": {
+                "icon_size": {
+                    "type": "integer",
+                    "example": 64
+                },
+                "image_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+                        "9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d"
+                    ]
+                },
+                "padding": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "request.Template": {
+            "type": "object",
+            "properties": {
+                "background": {
+                    "type": "string",
+                    "example": "#ffffff"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 630
+                },
+                "layers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.TemplateLayer"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "product-card"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "request.TemplateLayer": {
+            "type": "object",
+            "properties": {
+                "align": {
+                    "type": "string",
+                    "enum": [
+                        "left",
+                        "center",
+                        "right"
+                    ],
+                    "example": "left"
+                },
+                "color": {
+                    "type": "string",
+                    "example": "#111111"
+                },
+                "fit": {
+                    "type": "string",
+                    "enum": [
+                        "fill",
+                        "fit",
+                        "stretch"
+                    ],
+                    "example": "fill"
+                },
+                "font": {
+                    "type": "string",
+                    "enum": [
+                        "regular",
+                        "bold",
+                        "italic",
+                        "mono"
+                    ],
+                    "example": "bold"
+                },
+                "font_size": {
+                    "type": "number",
+                    "example": 56
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 510
+                },
+                "image_id": {
+                    "type": "string",
+                    "example": "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+                },
+                "line_height": {
+                    "type": "number",
+                    "example": 1.2
+                },
+                "max_lines": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "example": "product"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "New arrival"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "base",
+                        "slot",
+                        "text"
+                    ],
+                    "example": "slot"
+                },
+                "valign": {
+                    "type": "string",
+                    "enum": [
+                        "top",
+                        "middle",
+                        "bottom"
+                    ],
+                    "example": "middle"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 510
+                },
+                "x": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "y": {
+                    "type": "integer",
+                    "example": 60
+                }
+            }
+        },
+        "response.BatchDelete": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.BatchDeleteItem"
+                    }
+                }
+            }
+        },
+        "response.BatchDeleteItem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "image_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.BatchUpload": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "почему пакет прерван: файлы после ошибки не прочитаны и не попали в results",
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.BatchUploadItem"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.BatchUploadItem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "HTTP-код, который вернул бы одиночный /upload",
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "image": {
+                    "$ref": "#/definitions/response.ProcessImage"
+                },
+                "index": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.Comparison": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "psnr": {
+                    "type": "number",
+                    "example": 38.42
+                },
+                "ssim": {
+                    "type": "number",
+                    "example": 0.9731
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 800
+                }
+            }
+        },
+        "response.Error": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid request body"
+                }
+            }
+        },
+        "response.ImageList": {
+            "type": "object",
+            "properties": {
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.ImageListItem"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.ImageListItem": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "image_id": {
+                    "type": "string"
+                },
+                "original_name": {
+                    "type": "string"
+                },
+                "quality": {
+                    "$ref": "#/definitions/entity.Quality"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.ImageStatus": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "failure_reason": {
+                    "type": "string"
+                },
+                "has_processed": {
+                    "type": "boolean"
+                },
+                "image_id": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/entity.Metadata"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "original_name": {
+                    "type": "string"
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "processed_content_type": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.ImageVersion": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "spec": {
+                    "type": "object"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.ImageVersions": {
+            "type": "object",
+            "properties": {
+                "image_id": {
+                    "type": "string"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.ImageVersion"
+                    }
+                }
+            }
+        },
+        "response.ProcessImage": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "image_id": {
+                    "type": "string"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "original_name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.Template": {
+            "type": "object",
+            "properties": {
+                "background": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "layers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.TemplateLayer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.WatermarkDetection": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "type": "number",
+                    "example": 0.91
+                },
+                "found": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "payload": {
+                    "type": "string",
+                    "example": "6f1c2f0e-8a4b-4c55-9a53-0d7f0f3b9c11"
                 }
             }
         }
@@ -6,7 +6,10 @@ require (
 	github.com/aws/aws-sdk-go-v2/credentials v1.19.7
 	github.com/google/uuid v1.6.0
 	github.com/rs/zerolog v1.34.0
-	golang.org/x/image v0.0.0-20191009234506-e7c1f5e7dbb8
+	github.com/srwiley/oksvg v0.0.0-20221011165216-be6e8873101c
+	github.com/srwiley/rasterx v0.0.0-20220730225603-2ab79fcdd4ef
+	github.com/swaggo/swag v1.16.4
+	golang.org/x/image v0.0.0-20211028202545-6944b10bf410
 )
 
 require (
@@ -45,7 +48,6 @@ require (
 	github.com/pierrec/lz4/v4 v4.1.15 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/swaggo/files/v2 v2.0.2 // indirect
-	github.com/swaggo/swag v1.16.4 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.51.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
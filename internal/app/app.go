@@ -12,6 +12,8 @@ import (
 	kafkactrl "github.com/andreyxaxa/Image-Processor/internal/controller/kafka"
 	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi"
 	"github.com/andreyxaxa/Image-Processor/internal/controller/worker/outbox"
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/internal/infrastructure/fetcher"
 	infrakafka "github.com/andreyxaxa/Image-Processor/internal/infrastructure/kafka"
 	"github.com/andreyxaxa/Image-Processor/internal/infrastructure/processor"
 	"github.com/andreyxaxa/Image-Processor/internal/repo/persistent"
@@ -56,12 +58,33 @@ func Run(cfg *config.Config) {
 		persistent.NewImageRepo(s3c, cfg.S3.Bucket),
 		persistent.NewImageMetadataRepo(pg),
 		persistent.NewOutboxImageMetadataRepo(pg),
+		persistent.NewTemplateRepo(pg),
+		persistent.NewImageVersionRepo(pg),
 		pg,
+		fetcher.New(
+			fetcher.Timeout(cfg.Fetcher.Timeout),
+			fetcher.MaxSize(cfg.Fetcher.MaxSize),
+			fetcher.MaxRedirects(cfg.Fetcher.MaxRedirects),
+			fetcher.AllowedHosts(cfg.Fetcher.AllowedHosts),
+		),
 		l,
 	)
 
 	// image processor use-case
-	imageProcessorUseCase := imageprocessor.New(processor.New())
+	background, err := processor.ParseHexColor(cfg.Processor.Background)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - processor.ParseHexColor: %w", err))
+	}
+	imageProcessorUseCase := imageprocessor.New(processor.New(
+		processor.Background(background),
+		processor.Metadata(dto.OutputMetadata{
+			Copyright:   cfg.Processor.Metadata.Copyright,
+			Artist:      cfg.Processor.Metadata.Artist,
+			Description: cfg.Processor.Metadata.Description,
+			SourceURL:   cfg.Processor.Metadata.SourceURL,
+			Custom:      cfg.Processor.Metadata.Custom,
+		}),
+	), l)
 
 	// Kafka Producer
 	kafkaProducer, err := producer.New(ctx, cfg.Kafka.Brokers)
@@ -101,8 +124,15 @@ func Run(cfg *config.Config) {
 	)
 
 	// HTTP Server
-	httpServer := httpserver.New(l, httpserver.Port(cfg.HTTP.Port), httpserver.Prefork(cfg.HTTP.UsePreforkMode))
-	restapi.NewRouter(httpServer.App, cfg, imageUseCase, l)
+	httpServer := httpserver.New(
+		l,
+		httpserver.Port(cfg.HTTP.Port),
+		httpserver.Prefork(cfg.HTTP.UsePreforkMode),
+		httpserver.BodyLimit(cfg.HTTP.BodyLimit),
+		httpserver.StreamRequestBody(true),
+		httpserver.ReadTimeout(cfg.HTTP.ReadTimeout),
+	)
+	restapi.NewRouter(httpServer.App, cfg, imageUseCase, imageProcessorUseCase, l)
 
 	// Start Components
 	err = outboxRelayWorker.Start(ctx)
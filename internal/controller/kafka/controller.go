@@ -111,10 +111,25 @@ func (c *KafkaController) processImage(ctx context.Context, event kafka.Message)
 		return fmt.Errorf("KafkaController - processImage - json.Unmarshal: %w", err)
 	}
 
-	// 1. скачиваем из S3
-	data, err := c.img.DownloadImageBytes(ctx, payload.OriginalKey)
-	if err != nil {
-		return fmt.Errorf("KafkaController - processImage - c.img.DownloadImageBytes: %w", err)
+	// 1. скачиваем из S3 оригинал или все исходники, если задача собирается из нескольких изображений
+	var data []byte
+	var sources [][]byte
+
+	if len(payload.SourceKeys) > 0 {
+		sources = make([][]byte, 0, len(payload.SourceKeys))
+		for _, key := range payload.SourceKeys {
+			src, err := c.img.DownloadImageBytes(ctx, key)
+			if err != nil {
+				return fmt.Errorf("KafkaController - processImage - c.img.DownloadImageBytes: %w", err)
+			}
+			sources = append(sources, src)
+		}
+	} else if payload.OriginalKey != "" {
+		// шаблон из одних текстовых слоев собирается без исходников
+		data, err = c.img.DownloadImageBytes(ctx, payload.OriginalKey)
+		if err != nil {
+			return fmt.Errorf("KafkaController - processImage - c.img.DownloadImageBytes: %w", err)
+		}
 	}
 
 	// 2. формируем dto, обрабатываем
@@ -122,17 +137,52 @@ func (c *KafkaController) processImage(ctx context.Context, event kafka.Message)
 	defer cpuCancel()
 	processed, err := c.prc.Process(cpuCtx, payload.ContentType, dto.Task{
 		Data:      data,
+		Sources:   sources,
 		Operation: payload.Operation,
 		Width:     payload.Width,
 		Height:    payload.Height,
 		Text:      payload.Text,
+		Collage:   payload.Collage,
+		Sprite:    payload.Sprite,
+		Quantize:  payload.Quantize,
+		Enhance:   payload.Enhance,
+		Trim:      payload.Trim,
+		Mark:      payload.Mark,
+		Tiles:     payload.Tiles,
+		Keying:    payload.Keying,
+		Transform: payload.Transform,
+		Annotate:  payload.Annotate,
+		Template:  payload.Template,
+		Output:    payload.Output,
+		Rasterize: payload.Rasterize,
+
+		AssessQuality: true,
 	})
 	if err != nil {
-		return fmt.Errorf("KafkaController - processImage - c.prc.Process: %w", err)
+		// ошибка самой обработки повтором не исправится: сохраняем причину, событие коммитим.
+		// если отменен внешний контекст (shutdown), событие оставляем на повтор
+		if ctx.Err() != nil {
+			return fmt.Errorf("KafkaController - processImage - c.prc.Process: %w", err)
+		}
+		c.logger.Error(err, "KafkaController - processImage - c.prc.Process")
+
+		err = c.img.MarkImageFailed(ctx, payload.ID, failureReason(err))
+		if err != nil {
+			return fmt.Errorf("KafkaController - processImage - c.img.MarkImageFailed: %w", err)
+		}
+
+		return nil
 	}
 
 	// 3. загружаем в S3 обработанное изображение, обновляем метаданные в бд
-	err = c.img.UploadProcessedImage(ctx, processed, payload.ID)
+	spec, err := payload.spec()
+	if err != nil {
+		return fmt.Errorf("KafkaController - processImage - payload.spec: %w", err)
+	}
+	err = c.img.UploadProcessedImage(ctx, processed, payload.ID, dto.OperationSpec{
+		Operation: payload.Operation,
+		Params:    spec,
+	})
 	if err != nil {
 		return fmt.Errorf("KafkaController - processImage - c.img.UploadProcessedImage: %w", err)
 	}
@@ -140,6 +190,21 @@ func (c *KafkaController) processImage(ctx context.Context, event kafka.Message)
 	return nil
 }
 
+// failureReason - причина для клиента: без цепочки вызовов, только исходная ошибка.
+func failureReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "processing timed out"
+	}
+
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
+			return err.Error()
+		}
+		err = inner
+	}
+}
+
 func (c *KafkaController) worker(tasks <-chan kafka.Message) {
 	defer c.wg.Done()
 
@@ -1,6 +1,12 @@
 package kafka
 
-import "github.com/google/uuid"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/google/uuid"
+)
 
 type ImageEventPayload struct {
 	ID          uuid.UUID `json:"id"`
@@ -10,4 +16,48 @@ type ImageEventPayload struct {
 	Width       *int      `json:"width,omitempty"`
 	Height      *int      `json:"height,omitempty"`
 	Text        *string   `json:"text,omitempty"`
+
+	// параметры отдельных операций
+	Quantize *dto.QuantizeOptions `json:"quantize,omitempty"`
+	Enhance  *dto.EnhanceOptions  `json:"enhance,omitempty"`
+	Trim     *dto.TrimOptions     `json:"trim,omitempty"`
+
+	Mark  *dto.InvisibleWatermarkOptions `json:"invisible_watermark,omitempty"`
+	Tiles *dto.TileOptions               `json:"tiles,omitempty"`
+
+	Keying    *dto.RemoveBackgroundOptions `json:"remove_background,omitempty"`
+	Transform *dto.TransformOptions        `json:"transform,omitempty"`
+	Annotate  *dto.AnnotateOptions         `json:"annotate,omitempty"`
+	Template  *dto.TemplateRender          `json:"template,omitempty"`
+	Output    *dto.OutputMetadata          `json:"output_metadata,omitempty"`
+
+	Rasterize *dto.RasterizeOptions `json:"rasterize,omitempty"`
+
+	// для операций над несколькими изображениями (коллаж, спрайт)
+	SourceKeys []string           `json:"source_keys,omitempty"`
+	Collage    *dto.CollageLayout `json:"collage,omitempty"`
+	Sprite     *dto.SpriteOptions `json:"sprite,omitempty"`
+}
+
+// spec - параметры операции без служебных полей (ключи S3, ID), сохраняются вместе с версией.
+func (p ImageEventPayload) spec() ([]byte, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("ImageEventPayload - spec - json.Marshal: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("ImageEventPayload - spec - json.Unmarshal: %w", err)
+	}
+	for _, key := range []string{"id", "original_key", "content_type", "source_keys"} {
+		delete(fields, key)
+	}
+
+	b, err = json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("ImageEventPayload - spec - json.Marshal: %w", err)
+	}
+
+	return b, nil
 }
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit ограничивает тело запроса limit байтами на всех маршрутах, кроме streamed.
+// Нужен при потоковом теле: fasthttp тогда не отклоняет большое тело сам, а ctx.Body() дочитал бы его целиком.
+// Тело остальных маршрутов дочитывается в память, и форма разбирается так же, как без потока.
+func BodyLimit(limit int, streamed ...string) fiber.Handler {
+	skip := make(map[string]bool, len(streamed))
+	for _, path := range streamed {
+		skip[path] = true
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		if skip[strings.TrimSuffix(ctx.Path(), "/")] {
+			return ctx.Next()
+		}
+
+		// 1. длина известна заранее
+		length := ctx.Request().Header.ContentLength()
+		if length > limit {
+			return tooLarge(ctx, limit)
+		}
+
+		stream := ctx.Context().RequestBodyStream()
+		if stream == nil {
+			return ctx.Next()
+		}
+
+		// 2. chunked: длину узнаем только дочитав, поэтому читаем не больше limit+1
+		if length < 0 {
+			body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+			if err != nil {
+				ctx.Context().SetConnectionClose()
+				return ctx.Status(http.StatusBadRequest).JSON(response.Error{Error: "problems with reading the request body"})
+			}
+			if len(body) > limit {
+				return tooLarge(ctx, limit)
+			}
+			ctx.Request().SetBody(body)
+
+			return ctx.Next()
+		}
+
+		// 3. длина в пределе - дочитываем тело в память
+		ctx.Request().Body()
+
+		return ctx.Next()
+	}
+}
+
+func tooLarge(ctx *fiber.Ctx, limit int) error {
+	// непрочитанный остаток тела остался в соединении - keep-alive дальше невозможен
+	ctx.Context().SetConnectionClose()
+
+	return ctx.Status(http.StatusRequestEntityTooLarge).
+		JSON(response.Error{Error: fmt.Sprintf("request body cant be more than %d bytes", limit)})
+}
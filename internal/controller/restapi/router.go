@@ -3,6 +3,7 @@ package restapi
 import (
 	"github.com/andreyxaxa/Image-Processor/config"
 	_ "github.com/andreyxaxa/Image-Processor/docs" // Swagger docs.
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/middleware"
 	v1 "github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1"
 	"github.com/andreyxaxa/Image-Processor/internal/usecase"
 	"github.com/andreyxaxa/Image-Processor/pkg/logger"
@@ -14,15 +15,18 @@ import (
 // @version 1.0.0
 // @host localhost:8080
 // @BasePath /v1
-func NewRouter(app *fiber.App, cfg *config.Config, img usecase.ImageUseCase, l logger.Interface) {
+func NewRouter(app *fiber.App, cfg *config.Config, img usecase.ImageUseCase, prc usecase.ImageProcessorUseCase, l logger.Interface) {
 	// Swagger
 	if cfg.Swagger.Enabled {
 		app.Get("/swagger/*", swagger.HandlerDefault)
 	}
 
+	// Body limit: тело пакетной загрузки читается потоком по частям, остальные - в пределах HTTP_BODY_LIMIT
+	app.Use(middleware.BodyLimit(cfg.HTTP.BodyLimit, "/v1/upload/batch"))
+
 	// Routers
 	apiV1Group := app.Group("/v1")
 	{
-		v1.NewImageRoutes(apiV1Group, img, l)
+		v1.NewImageRoutes(apiV1Group, img, prc, cfg.Preview, l)
 	}
 }
@@ -0,0 +1,385 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/request"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+// formFields - общие поля формы пакета, прочитанные до файлов.
+type formFields map[string]string
+
+func (f formFields) FormValue(key string, defaultValue ...string) string {
+	if v := f[key]; v != "" {
+		return v
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+
+	return ""
+}
+
+// fileForm - поля операции одного файла пакета поверх общих полей формы.
+type fileForm struct {
+	fields map[string]string
+	shared formValues
+}
+
+func (f fileForm) FormValue(key string, defaultValue ...string) string {
+	if v, ok := f.fields[key]; ok {
+		return v
+	}
+
+	return f.shared.FormValue(key, defaultValue...)
+}
+
+// batchUpload - файл пакета, прошедший проверки и ожидающий загрузки.
+type batchUpload struct {
+	result      *response.BatchUploadItem
+	name        string
+	data        []byte
+	contentType string
+	op          dto.Operation
+}
+
+// @Summary  	Batch upload
+// @Description Uploads many files in one request. Operation fields of the form apply to every file, operations overrides them per file. The body is read as a stream, part by part: form fields must precede the files, every file is validated and queued as soon as it is read. Results report success or the error of every file. If the batch is cut short (malformed body, field after a file, more files than allowed) the files read so far keep their results and error tells why the rest was not read
+// @Tags 		images
+// @Accept 		mpfd
+// @Produce 	json
+// @Param 		files 	   formData file   true  "Image files(jpg, png, gif, bmp, tiff, svg), up to 100, 10MB each"
+// @Param 		operation  formData string false "Operation for all files(same values and fields as /upload)"
+// @Param 		operations formData string false "Per-file fields as JSON array in the order of files, e.g. [{\"operation\":\"resize\",\"width\":200,\"height\":200},null]. null keeps the shared fields"
+// @Success 	200 {object} response.BatchUpload
+// @Failure 	400 {object} response.Error "No files, malformed form or operations"
+// @Failure 	415 {object} response.Error "Compressed body"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/upload/batch [post]
+func (r *V1) batchUpload(ctx *fiber.Ctx) error {
+	boundary := string(ctx.Request().Header.MultipartFormBoundary())
+	if boundary == "" {
+		ctx.Context().SetConnectionClose()
+		return errorResponse(ctx, http.StatusBadRequest, "multipart form is required")
+	}
+	if len(ctx.Request().Header.ContentEncoding()) > 0 {
+		ctx.Context().SetConnectionClose()
+		return errorResponse(ctx, http.StatusUnsupportedMediaType, "compressed request body is not supported")
+	}
+
+	// тело пакета не буферизуется целиком: читаем части по одной прямо из соединения
+	body := ctx.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(ctx.Body())
+	}
+	mr := multipart.NewReader(body, boundary)
+
+	var (
+		shared    = formFields{}
+		overrides []map[string]string
+		fields    int
+		batchErr  string
+	)
+	// емкость фиксирована: append не переносит массив, и указатели на элементы остаются верными
+	results := make([]response.BatchUploadItem, 0, validate.MaxBatchFiles)
+
+	g := errgroup.Group{}
+	g.SetLimit(validate.BatchConcurrency)
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			batchErr = "malformed multipart form"
+			break
+		}
+
+		if part.FormName() != "files" {
+			if fields++; fields > validate.MaxBatchFields {
+				batchErr = fmt.Sprintf("form cant have more than %d fields", validate.MaxBatchFields)
+				break
+			}
+		}
+
+		// 1. поля формы: общие поля операции и operations, только до файлов
+		if part.FileName() == "" {
+			if len(results) > 0 {
+				batchErr = "form fields must precede files"
+				break
+			}
+
+			value, err := io.ReadAll(io.LimitReader(part, validate.MaxBatchFieldSize+1))
+			if err != nil {
+				batchErr = "problems with reading the request body"
+				break
+			}
+			if int64(len(value)) > validate.MaxBatchFieldSize {
+				batchErr = fmt.Sprintf("form field cant be more than %d bytes", validate.MaxBatchFieldSize)
+				break
+			}
+
+			if part.FormName() == "operations" {
+				overrides, err = parseBatchOperations(string(value))
+				if err != nil {
+					batchErr = err.Error()
+					break
+				}
+				continue
+			}
+			shared[part.FormName()] = string(value)
+
+			continue
+		}
+		if part.FormName() != "files" {
+			continue
+		}
+
+		// 2. файл: проверяем сразу, ошибка файла попадает в его результат, а не валит пакет
+		if len(results) == validate.MaxBatchFiles {
+			batchErr = fmt.Sprintf("cant upload more than %d files at once", validate.MaxBatchFiles)
+			break
+		}
+
+		i := len(results)
+		results = append(results, response.BatchUploadItem{Index: i, FileName: part.FileName()})
+		result := &results[i]
+
+		data, err := io.ReadAll(io.LimitReader(part, validate.MaxFileSize+1))
+		if err != nil {
+			result.Code, result.Error = http.StatusBadRequest, "file was not read completely"
+			batchErr = "problems with reading the request body"
+			break
+		}
+
+		var fileFields map[string]string
+		if overrides != nil {
+			if i >= len(overrides) {
+				result.Code, result.Error = http.StatusBadRequest, "operations has no entry for this file"
+				continue
+			}
+			fileFields = overrides[i]
+		}
+
+		u, uerr := r.checkBatchFile(ctx.UserContext(), part, data, fileForm{fields: fileFields, shared: shared})
+		if uerr != nil {
+			if uerr.err != nil {
+				r.logger.Error(uerr.err, "restapi - v1 - batchUpload")
+			}
+			result.Code, result.Error = uerr.code, uerr.msg
+			continue
+		}
+		u.result = result
+
+		// 3. загружаем параллельно с чтением следующих файлов; при занятых слотах чтение ждет
+		g.Go(func() error {
+			image, err := r.img.UploadNewImage(ctx.UserContext(), bytes.NewReader(u.data), u.name, u.contentType, int64(len(u.data)), u.op)
+			if err != nil {
+				r.logger.Error(err, "restapi - v1 - batchUpload")
+				u.result.Code, u.result.Error = http.StatusInternalServerError, "storage problems"
+
+				return nil
+			}
+
+			u.result.Code = http.StatusCreated
+			u.result.Image = &response.ProcessImage{
+				ImageID:      image.ID.String(),
+				OriginalName: image.OriginalName,
+				Size:         int(image.Size),
+				ContentType:  image.ContentType,
+				Status:       string(image.Status),
+				Operation:    u.op.Operation,
+				CreatedAt:    image.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			}
+
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	// пакет прерван: непрочитанный остаток тела остался в соединении - keep-alive дальше невозможен
+	if batchErr != "" {
+		ctx.Context().SetConnectionClose()
+	}
+
+	if len(results) == 0 {
+		if batchErr == "" {
+			batchErr = "files are required"
+		}
+		return errorResponse(ctx, http.StatusBadRequest, batchErr)
+	}
+	if batchErr == "" && len(overrides) > len(results) {
+		batchErr = fmt.Sprintf("operations has %d entries for %d files", len(overrides), len(results))
+	}
+
+	// 4. ответ
+	resp := response.BatchUpload{Results: results, Error: batchErr}
+	for _, res := range results {
+		if res.Image != nil {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
+
+// checkBatchFile проверяет прочитанный файл пакета так же, как одиночный /upload проверяет свой.
+func (r *V1) checkBatchFile(ctx context.Context, part *multipart.Part, data []byte, form formValues) (batchUpload, *uploadError) {
+	op, err := parseOperation(form)
+	if err != nil {
+		return batchUpload{}, &uploadError{code: http.StatusBadRequest, msg: err.Error()}
+	}
+
+	// 1. валидация размера
+	if len(data) == 0 {
+		return batchUpload{}, &uploadError{code: http.StatusBadRequest, msg: "file is empty"}
+	}
+	if int64(len(data)) > validate.MaxFileSize {
+		return batchUpload{}, &uploadError{
+			code: http.StatusRequestEntityTooLarge,
+			msg:  fmt.Sprintf("file size cant be more than %d bytes", validate.MaxFileSize),
+		}
+	}
+
+	// 2. формат по содержимому, расширение, SVG
+	reader := bytes.NewReader(data)
+	contentType, uerr := checkUpload(part.FileName(), part.Header.Get("Content-Type"), reader)
+	if uerr != nil {
+		return batchUpload{}, uerr
+	}
+
+	if contentType == validate.SVGContentType {
+		opts, err := parseRasterizeOptions(form)
+		if err != nil {
+			return batchUpload{}, &uploadError{code: http.StatusBadRequest, msg: err.Error()}
+		}
+		op.Rasterize = &opts
+	}
+
+	// 3. размер результата transform
+	if uerr := r.checkTransformOutput(ctx, op, reader, contentType); uerr != nil {
+		return batchUpload{}, uerr
+	}
+
+	return batchUpload{name: part.FileName(), data: data, contentType: contentType, op: op}, nil
+}
+
+// parseBatchOperations разбирает поля операций по файлам. Нестроковые значения (числа, bool,
+// JSON для shapes и custom_metadata) передаются парсерам операции текстом, как пришли бы из формы.
+func parseBatchOperations(raw string) ([]map[string]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return nil, errors.New("operations must be a JSON array of objects")
+	}
+	if len(items) > validate.MaxBatchFiles {
+		return nil, fmt.Errorf("operations cant have more than %d entries", validate.MaxBatchFiles)
+	}
+
+	overrides := make([]map[string]string, len(items))
+	for i, item := range items {
+		if item == nil {
+			continue
+		}
+
+		fields := make(map[string]string, len(item))
+		for key, value := range item {
+			var str string
+			if err := json.Unmarshal(value, &str); err == nil {
+				fields[key] = str
+			} else {
+				fields[key] = string(value)
+			}
+		}
+		overrides[i] = fields
+	}
+
+	return overrides, nil
+}
+
+// @Summary  	Batch delete
+// @Description Deletes many images. Every ID is deleted on its own: results report success or the error of every ID
+// @Tags 		images
+// @Accept 		json
+// @Produce 	json
+// @Param 		request body request.BatchDelete true "Image IDs, up to 1000"
+// @Success 	200 {object} response.BatchDelete
+// @Failure 	400 {object} response.Error "Invalid body, no IDs or too many IDs"
+// @Router 		/v1/images/delete [post]
+func (r *V1) batchDelete(ctx *fiber.Ctx) error {
+	var body request.BatchDelete
+	if err := ctx.BodyParser(&body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid request body")
+	}
+
+	// 1. валидация количества
+	if len(body.ImageIDs) == 0 {
+		return errorResponse(ctx, http.StatusBadRequest, "image_ids is required")
+	}
+	if len(body.ImageIDs) > validate.MaxBatchDelete {
+		return errorResponse(ctx, http.StatusBadRequest,
+			fmt.Sprintf("cant delete more than %d images at once", validate.MaxBatchDelete))
+	}
+
+	// 2. удаляем параллельно, неверный ID - ошибка только этого элемента
+	results := make([]response.BatchDeleteItem, len(body.ImageIDs))
+	g := errgroup.Group{}
+	g.SetLimit(validate.BatchConcurrency)
+	for i, idStr := range body.ImageIDs {
+		results[i] = response.BatchDeleteItem{ImageID: idStr}
+
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			results[i].Code, results[i].Error = http.StatusBadRequest, "invalid id"
+			continue
+		}
+
+		g.Go(func() error {
+			err := r.img.DeleteImage(ctx.UserContext(), id)
+			switch {
+			case err == nil:
+				results[i].Code = http.StatusNoContent
+			case errors.Is(err, errs.ErrRecordNotFound):
+				results[i].Code, results[i].Error = http.StatusNotFound, "image not found"
+			default:
+				r.logger.Error(err, "restapi - v1 - batchDelete")
+				results[i].Code, results[i].Error = http.StatusInternalServerError, "problem storage"
+			}
+
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	// 3. ответ
+	resp := response.BatchDelete{Results: results}
+	for _, res := range results {
+		if res.Code == http.StatusNoContent {
+			resp.Deleted++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/request"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary  	Create collage
+// @Description Composes existing images into one grid image. Processing goes through outbox -> kafka -> worker, result is a new image
+// @Tags 		images
+// @Accept 		json
+// @Produce 	json
+// @Param 		request body request.Collage true "Source image IDs and grid layout"
+// @Success 	201 {object} response.ProcessImage
+// @Failure 	400 {object} response.Error "Wrong parameters"
+// @Failure 	404 {object} response.Error "Source image not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/collage [post]
+func (r *V1) createCollage(ctx *fiber.Ctx) error {
+	var body request.Collage
+	if err := ctx.BodyParser(&body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid request body")
+	}
+
+	// 1. валидация id исходников
+	IDs, err := parseImageIDs(body.ImageIDs)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	// 2. валидация сетки
+	if body.Rows < 1 || body.Columns < 1 || body.Rows*body.Columns > validate.MaxCollageCells {
+		return errorResponse(ctx, http.StatusBadRequest,
+			fmt.Sprintf("rows and columns must be positive, rows*columns can't be more than %d", validate.MaxCollageCells))
+	}
+
+	if len(IDs) > body.Rows*body.Columns {
+		return errorResponse(ctx, http.StatusBadRequest, "too many images for the grid")
+	}
+
+	if body.CellWidth < validate.MinCollageCellSize || body.CellWidth > validate.MaxCollageCellSize ||
+		body.CellHeight < validate.MinCollageCellSize || body.CellHeight > validate.MaxCollageCellSize {
+		return errorResponse(ctx, http.StatusBadRequest,
+			fmt.Sprintf("cell_width and cell_height must be between %d and %d", validate.MinCollageCellSize, validate.MaxCollageCellSize))
+	}
+
+	if body.Gap < 0 || body.Gap > validate.MaxCollageGap {
+		return errorResponse(ctx, http.StatusBadRequest,
+			fmt.Sprintf("gap must be between 0 and %d", validate.MaxCollageGap))
+	}
+
+	width := body.Columns*body.CellWidth + (body.Columns+1)*body.Gap
+	height := body.Rows*body.CellHeight + (body.Rows+1)*body.Gap
+	if width > validate.MaxResizeWidth || height > validate.MaxResizeHeight {
+		return errorResponse(ctx, http.StatusBadRequest,
+			fmt.Sprintf("collage can't be larger than %dx%d", validate.MaxResizeWidth, validate.MaxResizeHeight))
+	}
+
+	// 3. валидация оформления
+	if body.Background == "" {
+		body.Background = validate.DefaultCollageBackground
+	}
+	if !validate.HexColor(body.Background) {
+		return errorResponse(ctx, http.StatusBadRequest, "background must be a color in #RRGGBB or #RRGGBBAA format")
+	}
+
+	fit := strings.ToLower(body.Fit)
+	if fit == "" {
+		fit = validate.DefaultCollageFit
+	}
+	if !validate.AllowedCollageFits[fit] {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid fit. Allowed: fill, fit, stretch")
+	}
+
+	format := strings.ToLower(body.Format)
+	if format == "" {
+		format = validate.DefaultCollageFormat
+	}
+	contentType, ok := validate.OutputFormats[format]
+	if !ok {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid format. Allowed: jpeg, png")
+	}
+
+	layout := dto.CollageLayout{
+		Rows:       body.Rows,
+		Columns:    body.Columns,
+		CellWidth:  body.CellWidth,
+		CellHeight: body.CellHeight,
+		Gap:        body.Gap,
+		Background: body.Background,
+		Fit:        fit,
+	}
+
+	// 4. создаем
+	image, err := r.img.CreateCollage(ctx.UserContext(), IDs, contentType, layout)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "source image not found")
+		}
+		r.logger.Error(err, "restapi - v1 - createCollage")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	// 5. ответ
+	resp := response.ProcessImage{
+		ImageID:      image.ID.String(),
+		OriginalName: image.OriginalName,
+		Size:         int(image.Size),
+		ContentType:  image.ContentType,
+		Status:       string(image.Status),
+		Operation:    "collage",
+		CreatedAt:    image.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(resp)
+}
+
+// parseImageIDs разбирает id исходников. Текст ошибки можно отдавать клиенту как есть.
+func parseImageIDs(strs []string) (uuid.UUIDs, error) {
+	if len(strs) == 0 {
+		return nil, errors.New("image_ids is required")
+	}
+
+	IDs := make(uuid.UUIDs, 0, len(strs))
+	for _, idStr := range strs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id: %s", idStr)
+		}
+		IDs = append(IDs, id)
+	}
+
+	return IDs, nil
+}
@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	kindOriginal  = "original"
+	kindProcessed = "processed"
+)
+
+var errNoVersion = errors.New("requested version of the image doesn't exist")
+
+// @Summary 	Compare original and processed image
+// @Description Computes SSIM and PSNR between original and processed result(bigger one is resized to the smaller). With diff=true returns PNG heatmap, metrics are in X-SSIM / X-PSNR headers
+// @Tags 		compare
+// @Produce 	json,image/png
+// @Param 		id 	 path  string true  "Image ID(uuid)"
+// @Param 		diff query bool   false "Return visual diff heatmap"
+// @Success 	200 {object} response.Comparison
+// @Failure 	400 {object} response.Error "Invalid ID"
+// @Failure 	404 {object} response.Error "Image not found or not processed yet"
+// @Failure 	413 {object} response.Error "Image has too many pixels"
+// @Failure 	422 {object} response.Error "Images can't be decoded"
+// @Failure 	429 {object} response.Error "Too many inline operations in progress"
+// @Failure 	500 {object} response.Error "Internal"
+// @Failure 	503 {object} response.Error "Comparison timed out"
+// @Router 		/v1/image/{id}/compare [get]
+func (r *V1) compareWithOriginal(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	withDiff, err := strconv.ParseBool(ctx.Query("diff", "false"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "diff must be true or false")
+	}
+
+	image, err := r.img.GetImage(ctx.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "image not found")
+		}
+		r.logger.Error(err, "restapi - v1 - compareWithOriginal")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	original, err := r.loadImageBytes(ctx, image, kindOriginal)
+	if err != nil {
+		return r.compareLoadError(ctx, err)
+	}
+
+	processed, err := r.loadImageBytes(ctx, image, kindProcessed)
+	if err != nil {
+		return r.compareLoadError(ctx, err)
+	}
+
+	return r.compare(ctx, original, processed, withDiff)
+}
+
+// @Summary 	Compare two images
+// @Description Computes SSIM and PSNR between two images(bigger one is resized to the smaller). With diff=true returns PNG heatmap, metrics are in X-SSIM / X-PSNR headers
+// @Tags 		compare
+// @Produce 	json,image/png
+// @Param 		a 	 query string true  "First image ID(uuid)"
+// @Param 		b 	 query string true  "Second image ID(uuid)"
+// @Param 		kind query string false "Which version of both images to compare(default processed)" Enums(processed, original)
+// @Param 		diff query bool   false "Return visual diff heatmap"
+// @Success 	200 {object} response.Comparison
+// @Failure 	400 {object} response.Error "Invalid parameters"
+// @Failure 	404 {object} response.Error "Image not found or not processed yet"
+// @Failure 	413 {object} response.Error "Image has too many pixels"
+// @Failure 	422 {object} response.Error "Images can't be decoded"
+// @Failure 	429 {object} response.Error "Too many inline operations in progress"
+// @Failure 	500 {object} response.Error "Internal"
+// @Failure 	503 {object} response.Error "Comparison timed out"
+// @Router 		/v1/compare [get]
+func (r *V1) compareImages(ctx *fiber.Ctx) error {
+	idA, err := uuid.Parse(ctx.Query("a"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id a")
+	}
+
+	idB, err := uuid.Parse(ctx.Query("b"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id b")
+	}
+
+	kind := ctx.Query("kind", kindProcessed)
+	if kind != kindProcessed && kind != kindOriginal {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid kind. Allowed: processed, original")
+	}
+
+	withDiff, err := strconv.ParseBool(ctx.Query("diff", "false"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "diff must be true or false")
+	}
+
+	data := make([][]byte, 0, 2)
+	for _, id := range []uuid.UUID{idA, idB} {
+		image, err := r.img.GetImage(ctx.UserContext(), id)
+		if err != nil {
+			if errors.Is(err, errs.ErrRecordNotFound) {
+				return errorResponse(ctx, http.StatusNotFound, "image not found")
+			}
+			r.logger.Error(err, "restapi - v1 - compareImages")
+
+			return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+		}
+
+		b, err := r.loadImageBytes(ctx, image, kind)
+		if err != nil {
+			return r.compareLoadError(ctx, err)
+		}
+		data = append(data, b)
+	}
+
+	return r.compare(ctx, data[0], data[1], withDiff)
+}
+
+func (r *V1) loadImageBytes(ctx *fiber.Ctx, image *entity.Image, kind string) ([]byte, error) {
+	key := image.OriginalKey
+	if kind == kindProcessed {
+		if image.Status != entity.Processed || image.ProcessedKey == nil {
+			return nil, errNoVersion
+		}
+		key = *image.ProcessedKey
+	}
+
+	if key == "" {
+		return nil, errNoVersion
+	}
+
+	return r.img.DownloadImageBytes(ctx.UserContext(), key)
+}
+
+func (r *V1) compareLoadError(ctx *fiber.Ctx, err error) error {
+	if errors.Is(err, errNoVersion) {
+		return errorResponse(ctx, http.StatusNotFound, "image not processed yet or has no original")
+	}
+	r.logger.Error(err, "restapi - v1 - compare")
+
+	return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+}
+
+func (r *V1) compare(ctx *fiber.Ctx, a, b []byte, withDiff bool) error {
+	// размеры по заголовкам до декодирования: 10 МБ PNG может развернуться в гигабайты
+	for _, data := range [][]byte{a, b} {
+		if uerr := checkPixels(data, validate.MaxInlinePixels); uerr != nil {
+			return errorResponse(ctx, uerr.code, uerr.msg)
+		}
+	}
+
+	release, uerr := r.acquireSlot(ctx)
+	if uerr != nil {
+		return errorResponse(ctx, uerr.code, uerr.msg)
+	}
+	defer release()
+
+	cpuCtx, cpuCancel := context.WithTimeout(ctx.UserContext(), r.preview.Timeout)
+	defer cpuCancel()
+	res, err := r.prc.Compare(cpuCtx, a, b, withDiff)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return errorResponse(ctx, http.StatusServiceUnavailable, "comparison timed out")
+		}
+		r.logger.Error(err, "restapi - v1 - compare")
+
+		return errorResponse(ctx, http.StatusUnprocessableEntity, "images can't be compared")
+	}
+
+	if withDiff {
+		return sendDiff(ctx, res)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(response.Comparison{
+		SSIM:   res.SSIM,
+		PSNR:   res.PSNR,
+		Width:  res.Width,
+		Height: res.Height,
+	})
+}
+
+func sendDiff(ctx *fiber.Ctx, res dto.Comparison) error {
+	ctx.Set("X-SSIM", strconv.FormatFloat(res.SSIM, 'f', 4, 64))
+	ctx.Set("X-PSNR", strconv.FormatFloat(res.PSNR, 'f', 2, 64))
+	ctx.Set(fiber.HeaderContentType, "image/png")
+
+	return ctx.Send(res.Diff)
+}
@@ -1,11 +1,16 @@
 package v1
 
 import (
+	"github.com/andreyxaxa/Image-Processor/config"
 	"github.com/andreyxaxa/Image-Processor/internal/usecase"
 	"github.com/andreyxaxa/Image-Processor/pkg/logger"
 )
 
 type V1 struct {
 	img    usecase.ImageUseCase
+	prc    usecase.ImageProcessorUseCase
 	logger logger.Interface
+
+	preview      config.Preview
+	previewSlots chan struct{} // ограничивает число превью, обрабатываемых одновременно
 }
@@ -0,0 +1,52 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary 	Get result file
+// @Description Downloads an additional file of the processing result: sprite.json for sprite, PNG icons and site.webmanifest for favicon
+// @Tags 		images
+// @Produce 	application/json,application/manifest+json,image/png
+// @Param 		id 	 path string true "Image ID(uuid)"
+// @Param 		name path string true "File name" example(sprite.json)
+// @Success 	200 {file} 	binary
+// @Failure 	400 {object} response.Error "Invalid ID or name"
+// @Failure 	404 {object} response.Error "File not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/image/{id}/files/{name} [get]
+func (r *V1) getFile(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	name := ctx.Params("name")
+	if !validate.FileName.MatchString(name) {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid file name")
+	}
+
+	body, err := r.img.DownloadFile(ctx.UserContext(), id, name)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "file not found")
+		}
+		r.logger.Error(err, "restapi - v1 - getFile")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	if contentType, ok := validate.FileContentTypes[path.Ext(name)]; ok {
+		ctx.Set(fiber.HeaderContentType, contentType)
+	}
+	ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="`+name+`"`)
+
+	return ctx.SendStream(body)
+}
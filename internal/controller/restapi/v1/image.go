@@ -4,13 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"path/filepath"
-	"strconv"
-	"strings"
 
 	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
 	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
-	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
 	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -21,15 +18,46 @@ import (
 // @Tags 		images
 // @Accept 		mpfd
 // @Produce 	json
-// @Param 		file 	  formData file   true  "Image file(jpg, png)"
-// @Param 		operation formData string true  "Operation" Enums(resize, thumbnail, watermark)
+// @Param 		file 	  formData file   true  "Image file(jpg, png, gif, bmp, tiff, svg)"
+// @Param 		operation formData string true  "Operation" Enums(resize, thumbnail, watermark, quantize, auto_enhance, trim, invisible_watermark, tiles, favicon, remove_background, transform, annotate)
 // @Param 		text 	  formData string false "Text(required for watermark operation)"
-// @Param 		width 	  formData int    false "Width(required for resize operation)"
-// @Param 		height 	  formData int 	  false "Height(required for resize operation)"
+// @Param 		width 	  formData int    false "Width(required for resize operation, output width for transform)"
+// @Param 		height 	  formData int 	  false "Height(required for resize operation, output height for transform)"
+// @Param 		colors 	  formData int 	  false "Palette size for quantize(2-256, default 64)"
+// @Param 		algorithm formData string false "Quantize algorithm" Enums(median_cut, kmeans)
+// @Param 		dither 	  formData bool   false "Floyd-Steinberg dithering for quantize"
+// @Param 		format 	  formData string false "Quantize output format" Enums(png, gif)
+// @Param 		enhance   formData bool   false "Run auto_enhance before the operation"
+// @Param 		equalize  formData bool   false "Histogram equalization for auto_enhance"
+// @Param 		strength  formData number false "Equalization strength for auto_enhance(0-1, default 0.5)"
+// @Param 		white_balance formData bool false "Gray-world white balance for auto_enhance(default true)"
+// @Param 		payload   formData string false "Invisible watermark payload: uuid or string up to 16 bytes(default image ID)"
+// @Param 		layout 	  formData string false "Tile pyramid layout(default dzi)" Enums(dzi, xyz)
+// @Param 		tile_size formData int    false "Tile side in pixels(64-1024, default 256)"
+// @Param 		overlap   formData int    false "Tile overlap for dzi(0-8, default 1)"
+// @Param 		tile_format formData string false "Tile format(default jpeg)" Enums(jpeg, png)
+// @Param 		tolerance formData int    false "Color tolerance for trim(0-255, default 10) and remove_background(default 30)"
+// @Param 		key_color formData string false "Background color #RRGGBB for remove_background(default detected from borders)"
+// @Param 		feather   formData int    false "Soft edge width in pixels for remove_background(0-20, default 2)"
+// @Param 		contiguous formData bool  false "remove_background keys only the area connected to borders(default true)"
+// @Param 		padding   formData int    false "Border pixels kept after trim(default 0)"
+// @Param 		matrix    formData string false "Affine matrix a,b,c,d,e,f for transform: x'=a*x+b*y+c, y'=d*x+e*y+f"
+// @Param 		corners   formData string false "Source corners x,y for perspective transform: top-left, top-right, bottom-right, bottom-left"
+// @Param 		interpolation formData string false "Transform sampling(default bilinear)" Enums(bilinear, bicubic)
+// @Param 		fill      formData string false "Transform fill color #RRGGBB or #RRGGBBAA(default transparent)"
+// @Param 		shapes    formData string false "Annotate primitives as JSON array: [{\"type\":\"arrow\",\"x\":10,\"y\":10,\"x2\":80,\"y2\":60,\"color\":\"#ff0000\",\"stroke_width\":3}]. Types: rect, ellipse, line, arrow, text"
+// @Param 		copyright  formData string false "Copyright written to EXIF/XMP(overrides service default)"
+// @Param 		artist     formData string false "Artist written to EXIF/XMP(overrides service default)"
+// @Param 		description formData string false "Description written to EXIF/XMP(overrides service default)"
+// @Param 		source_url formData string false "Source URL written to XMP dc:source(overrides service default)"
+// @Param 		custom_metadata formData string false "Custom XMP fields as JSON object, e.g. {\"license\":\"CC-BY\"}"
+// @Param 		svg_width  formData int    false "SVG rasterization width(keeps aspect ratio if only one side is set)"
+// @Param 		svg_height formData int    false "SVG rasterization height"
+// @Param 		dpi 	   formData number false "SVG rasterization DPI when no size is set(default 96)"
 // @Success 	201 {object} response.ProcessImage
 // @Failure 	400 {object} response.Error "Empty file or wrong parameters"
-// @Failure 	413 {object} response.Error "File too large"
-// @Failure 	415 {object} response.Error "Unsupported format"
+// @Failure 	413 {object} response.Error "File or transform output too large"
+// @Failure 	415 {object} response.Error "Unsupported format or content doesn't match declared type/extension"
 // @Failure 	500 {object} response.Error "Internal"
 // @Router 		/v1/upload [post]
 func (r *V1) processImage(ctx *fiber.Ctx) error {
@@ -38,103 +66,38 @@ func (r *V1) processImage(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, http.StatusBadRequest, "file is required")
 	}
 
-	// 1. валидация размера
-	if file.Size == 0 {
-		return errorResponse(ctx, http.StatusBadRequest, "file is empty")
-	}
-
-	if file.Size > validate.MaxFileSize {
-		return errorResponse(ctx, http.StatusRequestEntityTooLarge,
-			fmt.Sprintf("file size cant be more than %d bytes", validate.MaxFileSize))
-	}
-
-	// 2. валидация content type
-	contentType := file.Header.Get("Content-Type")
-	if !validate.AllowedContentTypes[contentType] {
-		return errorResponse(ctx, http.StatusUnsupportedMediaType, "unsupported file type. Allowed: jpeg, png")
-	}
+	// 1-4. валидация размера, формата и расширения
+	fileReader, contentType, uerr := openUpload(file)
+	if uerr != nil {
+		if uerr.err != nil {
+			r.logger.Error(uerr.err, "restapi - v1 - processImage")
+		}
 
-	// 3. валидация расширения
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !validate.AllowedExtensions[ext] {
-		return errorResponse(ctx, http.StatusUnsupportedMediaType, "unsupported file extension. Allowed: .jpg, .jpeg, .png")
+		return errorResponse(ctx, uerr.code, uerr.msg)
 	}
+	defer fileReader.Close()
 
-	// 4. валидация операции
-	operation := strings.ToLower(ctx.FormValue("operation"))
-	if operation == "" {
-		return errorResponse(ctx, http.StatusBadRequest, "operation is required")
+	// 5. валидация операции
+	op, err := parseOperation(ctx)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
 	}
 
-	var op dto.Operation
-
-	switch operation {
-	case "resize":
-		// width
-		widthStr := ctx.FormValue("width")
-		if widthStr == "" {
-			return errorResponse(ctx, http.StatusBadRequest, "width is required for resize")
-		}
-		width, err := strconv.Atoi(widthStr)
-		if err != nil {
-			return errorResponse(ctx, http.StatusBadRequest, "width must be a number")
-		}
-		if width < validate.MinResizeWidth || width > validate.MaxResizeWidth {
-			return errorResponse(ctx, http.StatusBadRequest,
-				fmt.Sprintf("width must be between %d and %d", validate.MinResizeWidth, validate.MaxResizeWidth))
-		}
-
-		// height
-		heightStr := ctx.FormValue("height")
-		if heightStr == "" {
-			return errorResponse(ctx, http.StatusBadRequest, "height is required for resize")
-		}
-		height, err := strconv.Atoi(heightStr)
+	if contentType == validate.SVGContentType {
+		opts, err := parseRasterizeOptions(ctx)
 		if err != nil {
-			return errorResponse(ctx, http.StatusBadRequest, "height must be a number")
-		}
-		if height < validate.MinResizeHeight || height > validate.MaxResizeHeight {
-			return errorResponse(ctx, http.StatusBadRequest,
-				fmt.Sprintf("height must be between %d and %d", validate.MinResizeHeight, validate.MaxResizeHeight))
-		}
-
-		op = dto.Operation{
-			Operation: "resize",
-			Width:     &width,
-			Height:    &height,
-		}
-	case "thumbnail":
-		op = dto.Operation{
-			Operation: "thumbnail",
-		}
-	case "watermark":
-		// text
-		textStr := ctx.FormValue("text")
-		if textStr == "" {
-			return errorResponse(ctx, http.StatusBadRequest, "text is required for watermark")
-		}
-
-		if len(textStr) < validate.MinTextLen || len(textStr) > validate.MaxTextLen {
-			return errorResponse(ctx, http.StatusBadRequest,
-				fmt.Sprintf("text length must be between %d and %d", validate.MinTextLen, validate.MaxTextLen))
+			return errorResponse(ctx, http.StatusBadRequest, err.Error())
 		}
-
-		op = dto.Operation{
-			Operation: "watermark",
-			Text:      &textStr,
-		}
-	default:
-		return errorResponse(ctx, http.StatusBadRequest, "invalid operation. Allowed: resize, thumbnail, watermark")
+		op.Rasterize = &opts
 	}
 
-	// 5. открытие файла
-	fileReader, err := file.Open()
-	if err != nil {
-		r.logger.Error(err, "restapi - v1 - processImage")
+	if uerr := r.checkTransformOutput(ctx.UserContext(), op, fileReader, contentType); uerr != nil {
+		if uerr.err != nil {
+			r.logger.Error(uerr.err, "restapi - v1 - processImage")
+		}
 
-		return errorResponse(ctx, http.StatusInternalServerError, "problems with opening the file")
+		return errorResponse(ctx, uerr.code, uerr.msg)
 	}
-	defer fileReader.Close()
 
 	// 6. загружаем
 	image, err := r.img.UploadNewImage(ctx.UserContext(), fileReader, file.Filename, contentType, file.Size, op)
@@ -159,13 +122,15 @@ func (r *V1) processImage(ctx *fiber.Ctx) error {
 }
 
 // @Summary 	Get processed image
-// @Description Downloads processed image from S3 by key
+// @Description Downloads processed image from S3 by key. While the image is still in the queue responds 202 with its status and Location of the status resource
 // @Tags 		images
-// @Produce 	image/jpeg,image/png
+// @Produce 	image/jpeg,image/png,image/gif,image/bmp,image/tiff,image/x-icon
 // @Param 		id path string true "Image ID(uuid)"
 // @Success 	200 {file} 	binary
+// @Success 	202 {object} response.ImageStatus "Not processed yet"
 // @Failure 	400 {object} response.Error "Invalid ID"
 // @Failure 	404 {object} response.Error "Image not found"
+// @Failure 	422 {object} response.Error "Processing failed"
 // @Failure 	500 {object} response.Error "Internal"
 // @Router 		/v1/image/{id} [get]
 func (r *V1) getProcessedImage(ctx *fiber.Ctx) error {
@@ -183,7 +148,7 @@ func (r *V1) getProcessedImage(ctx *fiber.Ctx) error {
 	processedKey, contentType, err := r.img.GetProcessedKeyByID(ctx.UserContext(), id)
 	if err != nil {
 		if errors.Is(err, errs.ErrRecordNotFound) {
-			return errorResponse(ctx, http.StatusNotFound, "image not found or not processed yet")
+			return r.notProcessedResponse(ctx, id)
 		}
 		r.logger.Error(err, "restapi - v1 - getProcessedImage")
 
@@ -200,6 +165,33 @@ func (r *V1) getProcessedImage(ctx *fiber.Ctx) error {
 	return ctx.SendStream(body)
 }
 
+// notProcessedResponse отличает неизвестный ID от изображения, которое еще в очереди или не обработалось.
+func (r *V1) notProcessedResponse(ctx *fiber.Ctx, id uuid.UUID) error {
+	image, err := r.img.GetImage(ctx.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "image not found")
+		}
+		r.logger.Error(err, "restapi - v1 - notProcessedResponse")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	if image.Status == entity.Failed {
+		reason := "unknown reason"
+		if image.FailureReason != nil {
+			reason = *image.FailureReason
+		}
+
+		return errorResponse(ctx, http.StatusUnprocessableEntity, "image processing failed: "+reason)
+	}
+
+	ctx.Set(fiber.HeaderLocation, fmt.Sprintf("/v1/image/%s/status", id))
+	ctx.Set(fiber.HeaderRetryAfter, pendingRetryAfter)
+
+	return ctx.Status(http.StatusAccepted).JSON(imageStatusResponse(image))
+}
+
 // @Summary 	Delete image
 // @Description Deletes image from all storages(S3, postgres(main table + outbox(cascade)))
 // @Tags 		images
@@ -0,0 +1,202 @@
+package v1
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary  	List images
+// @Description Lists images, newest first, with cursor pagination. Pass next_cursor from the previous page as cursor. Quality filters apply to images whose original was assessed during processing
+// @Tags 		images
+// @Produce 	json
+// @Param 		status 		  query  string false "Status" Enums(pending, processed, failed)
+// @Param 		content_type  query  string false "Original content type, e.g. image/png"
+// @Param 		created_from  query  string false "Created at or after, RFC3339"
+// @Param 		created_to 	  query  string false "Created before, RFC3339"
+// @Param 		name_prefix   query  string false "Original file name prefix(case-sensitive)"
+// @Param 		min_size 	  query  int 	false "Minimum original size in bytes"
+// @Param 		max_size 	  query  int 	false "Maximum original size in bytes"
+// @Param 		quality_grade query  string false "Quality grade" Enums(good, fair, poor)
+// @Param 		quality_issue query  string false "Quality issue" Enums(blurry, overexposed, underexposed, low_resolution)
+// @Param 		min_sharpness query  number false "Minimum sharpness(variance of Laplacian)"
+// @Param 		max_sharpness query  number false "Maximum sharpness(variance of Laplacian)"
+// @Param 		cursor 		  query  string false "Page cursor(next_cursor of the previous page)"
+// @Param 		limit 		  query  int 	false "Page size(1-200, default 50)"
+// @Success 	200 {object} response.ImageList
+// @Failure 	400 {object} response.Error "Wrong parameters"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/images [get]
+func (r *V1) listImages(ctx *fiber.Ctx) error {
+	filter, err := parseImageFilter(ctx)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	page, err := r.img.ListImages(ctx.UserContext(), filter)
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - listImages")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.ImageList{
+		Images: make([]response.ImageListItem, 0, len(page.Images)),
+	}
+	if page.Next != nil {
+		resp.NextCursor = encodeImageCursor(*page.Next)
+	}
+	for _, image := range page.Images {
+		resp.Images = append(resp.Images, response.ImageListItem{
+			ImageID:      image.ID.String(),
+			OriginalName: image.OriginalName,
+			Size:         int(image.Size),
+			ContentType:  image.ContentType,
+			Status:       string(image.Status),
+			Quality:      image.Metadata.Quality,
+			CreatedAt:    image.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	return ctx.JSON(resp)
+}
+
+// parseImageFilter собирает фильтр из query-параметров. Текст ошибки можно отдавать клиенту как есть.
+func parseImageFilter(ctx *fiber.Ctx) (dto.ImageFilter, error) {
+	filter := dto.ImageFilter{
+		Status:       entity.Status(strings.ToLower(ctx.Query("status"))),
+		NamePrefix:   ctx.Query("name_prefix"),
+		QualityGrade: strings.ToLower(ctx.Query("quality_grade")),
+		QualityIssue: strings.ToLower(ctx.Query("quality_issue")),
+		Limit:        validate.DefaultListLimit,
+	}
+
+	if filter.Status != "" && !validate.AllowedListStatuses[string(filter.Status)] {
+		return dto.ImageFilter{}, errors.New("invalid status. Allowed: pending, processed, failed")
+	}
+
+	if str := ctx.Query("content_type"); str != "" {
+		filter.ContentType = validate.NormalizeContentType(str)
+		if !validate.AllowedContentTypes[filter.ContentType] {
+			return dto.ImageFilter{}, errors.New("invalid content_type. Allowed: image/jpeg, image/png, image/gif, image/bmp, image/tiff, image/svg+xml")
+		}
+	}
+
+	if len(filter.NamePrefix) > validate.MaxNamePrefixLen {
+		return dto.ImageFilter{}, fmt.Errorf("name_prefix cant be longer than %d bytes", validate.MaxNamePrefixLen)
+	}
+
+	for key, dst := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+	} {
+		str := ctx.Query(key)
+		if str == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, str)
+		if err != nil {
+			return dto.ImageFilter{}, fmt.Errorf("%s must be RFC3339 time, e.g. 2025-01-02T15:04:05Z", key)
+		}
+		// created_at пишется в UTC в колонку без зоны (UploadNewImage), сравниваем в UTC
+		t = t.UTC()
+		*dst = &t
+	}
+
+	for key, dst := range map[string]**int64{
+		"min_size": &filter.MinSize,
+		"max_size": &filter.MaxSize,
+	} {
+		str := ctx.Query(key)
+		if str == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(str, 10, 64)
+		if err != nil || v < 0 {
+			return dto.ImageFilter{}, fmt.Errorf("%s must be a non-negative integer", key)
+		}
+		*dst = &v
+	}
+
+	if filter.QualityGrade != "" && !validate.AllowedQualityGrades[filter.QualityGrade] {
+		return dto.ImageFilter{}, errors.New("invalid quality_grade. Allowed: good, fair, poor")
+	}
+	if filter.QualityIssue != "" && !validate.AllowedQualityIssues[filter.QualityIssue] {
+		return dto.ImageFilter{}, errors.New("invalid quality_issue. Allowed: blurry, overexposed, underexposed, low_resolution")
+	}
+
+	for key, dst := range map[string]**float64{
+		"min_sharpness": &filter.MinSharpness,
+		"max_sharpness": &filter.MaxSharpness,
+	} {
+		str := ctx.Query(key)
+		if str == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return dto.ImageFilter{}, fmt.Errorf("%s must be a number", key)
+		}
+		*dst = &v
+	}
+
+	if str := ctx.Query("cursor"); str != "" {
+		cursor, err := decodeImageCursor(str)
+		if err != nil {
+			return dto.ImageFilter{}, errors.New("invalid cursor")
+		}
+		filter.After = &cursor
+	}
+
+	if str := ctx.Query("limit"); str != "" {
+		limit, err := parseInt("limit", str, 1, validate.MaxListLimit)
+		if err != nil {
+			return dto.ImageFilter{}, err
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// курсор непрозрачен для клиента: время создания в микросекундах (точность postgres) и id
+func encodeImageCursor(c dto.ImageCursor) string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixMicro(), 10) + "_" + c.ID.String()
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeImageCursor(s string) (dto.ImageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return dto.ImageCursor{}, fmt.Errorf("decodeImageCursor - base64.DecodeString: %w", err)
+	}
+
+	micros, idStr, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return dto.ImageCursor{}, errors.New("decodeImageCursor - malformed cursor")
+	}
+
+	usec, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return dto.ImageCursor{}, fmt.Errorf("decodeImageCursor - strconv.ParseInt: %w", err)
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return dto.ImageCursor{}, fmt.Errorf("decodeImageCursor - uuid.Parse: %w", err)
+	}
+
+	return dto.ImageCursor{CreatedAt: time.UnixMicro(usec).UTC(), ID: id}, nil
+}
@@ -0,0 +1,573 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/google/uuid"
+)
+
+// formValues - источник полей операции: форма запроса или поля одного файла пакетной загрузки.
+type formValues interface {
+	FormValue(key string, defaultValue ...string) string
+}
+
+// parseOperation собирает и валидирует операцию из полей формы.
+// Текст ошибки можно отдавать клиенту как есть.
+func parseOperation(form formValues) (dto.Operation, error) {
+	operation := strings.ToLower(form.FormValue("operation"))
+	if operation == "" {
+		return dto.Operation{}, errors.New("operation is required")
+	}
+
+	op, err := parseBaseOperation(form, operation)
+	if err != nil {
+		return dto.Operation{}, err
+	}
+
+	// автоулучшение перед любой другой операцией
+	if op.Enhance == nil {
+		enhance, err := optionalBool(form, "enhance", false)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+		if enhance {
+			opts, err := parseEnhanceOptions(form)
+			if err != nil {
+				return dto.Operation{}, err
+			}
+			op.Enhance = &opts
+		}
+	}
+
+	output, err := parseOutputMetadata(form)
+	if err != nil {
+		return dto.Operation{}, err
+	}
+	op.Output = output
+
+	return op, nil
+}
+
+func parseBaseOperation(form formValues, operation string) (dto.Operation, error) {
+	switch operation {
+	case "resize":
+		width, err := requiredInt(form, "width", operation, validate.MinResizeWidth, validate.MaxResizeWidth)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		height, err := requiredInt(form, "height", operation, validate.MinResizeHeight, validate.MaxResizeHeight)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		return dto.Operation{
+			Operation: "resize",
+			Width:     &width,
+			Height:    &height,
+		}, nil
+	case "thumbnail":
+		return dto.Operation{
+			Operation: "thumbnail",
+		}, nil
+	case "watermark":
+		textStr := form.FormValue("text")
+		if textStr == "" {
+			return dto.Operation{}, errors.New("text is required for watermark")
+		}
+
+		if len(textStr) < validate.MinTextLen || len(textStr) > validate.MaxTextLen {
+			return dto.Operation{}, fmt.Errorf("text length must be between %d and %d", validate.MinTextLen, validate.MaxTextLen)
+		}
+
+		return dto.Operation{
+			Operation: "watermark",
+			Text:      &textStr,
+		}, nil
+	case "quantize":
+		colors, err := optionalInt(form, "colors", validate.DefaultQuantizeColors, validate.MinQuantizeColors, validate.MaxQuantizeColors)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		algorithm := strings.ToLower(form.FormValue("algorithm", validate.DefaultQuantizeAlgorithm))
+		if !validate.AllowedQuantizeAlgorithms[algorithm] {
+			return dto.Operation{}, errors.New("invalid algorithm. Allowed: median_cut, kmeans")
+		}
+
+		dither, err := optionalBool(form, "dither", false)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		format := strings.ToLower(form.FormValue("format", validate.DefaultQuantizeFormat))
+		if !validate.AllowedQuantizeFormats[format] {
+			return dto.Operation{}, errors.New("invalid format. Allowed: png, gif")
+		}
+
+		return dto.Operation{
+			Operation: "quantize",
+			Quantize: &dto.QuantizeOptions{
+				Colors:    colors,
+				Algorithm: algorithm,
+				Dither:    dither,
+				Format:    format,
+			},
+		}, nil
+	case "trim":
+		tolerance, err := optionalInt(form, "tolerance", validate.DefaultTrimTolerance, 0, validate.MaxTrimTolerance)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		padding, err := optionalInt(form, "padding", 0, 0, validate.MaxTrimPadding)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		return dto.Operation{
+			Operation: "trim",
+			Trim: &dto.TrimOptions{
+				Tolerance: tolerance,
+				Padding:   padding,
+			},
+		}, nil
+	case "auto_enhance":
+		opts, err := parseEnhanceOptions(form)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		return dto.Operation{
+			Operation: "auto_enhance",
+			Enhance:   &opts,
+		}, nil
+	case "invisible_watermark":
+		// пустой payload - в знак попадет ID изображения
+		payload := form.FormValue("payload")
+		if _, err := uuid.Parse(payload); err != nil && len(payload) > validate.MaxMarkPayloadLen {
+			return dto.Operation{}, fmt.Errorf("payload must be an uuid or a string up to %d bytes", validate.MaxMarkPayloadLen)
+		}
+
+		return dto.Operation{
+			Operation: "invisible_watermark",
+			Mark: &dto.InvisibleWatermarkOptions{
+				Payload: payload,
+			},
+		}, nil
+	case "remove_background":
+		keyColor := form.FormValue("key_color")
+		if keyColor != "" && !validate.HexColor(keyColor) {
+			return dto.Operation{}, errors.New("key_color must be a color in #RRGGBB format")
+		}
+
+		tolerance, err := optionalInt(form, "tolerance", validate.DefaultKeyTolerance, 0, validate.MaxTrimTolerance)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		feather, err := optionalInt(form, "feather", validate.DefaultKeyFeather, 0, validate.MaxKeyFeather)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		contiguous, err := optionalBool(form, "contiguous", true)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		return dto.Operation{
+			Operation: "remove_background",
+			Keying: &dto.RemoveBackgroundOptions{
+				Color:      keyColor,
+				Tolerance:  tolerance,
+				Feather:    feather,
+				Contiguous: contiguous,
+			},
+		}, nil
+	case "transform":
+		opts, err := parseTransformOptions(form)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		return dto.Operation{
+			Operation: "transform",
+			Transform: &opts,
+		}, nil
+	case "annotate":
+		shapes, err := parseShapes(form)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		return dto.Operation{
+			Operation: "annotate",
+			Annotate: &dto.AnnotateOptions{
+				Shapes: shapes,
+			},
+		}, nil
+	case "favicon":
+		return dto.Operation{
+			Operation: "favicon",
+		}, nil
+	case "tiles":
+		layout := strings.ToLower(form.FormValue("layout", validate.DefaultTileLayout))
+		if !validate.AllowedTileLayouts[layout] {
+			return dto.Operation{}, errors.New("invalid layout. Allowed: dzi, xyz")
+		}
+
+		tileSize, err := optionalInt(form, "tile_size", validate.DefaultTileSize, validate.MinTileSize, validate.MaxTileSize)
+		if err != nil {
+			return dto.Operation{}, err
+		}
+
+		// в xyz тайлы не перекрываются
+		overlap := 0
+		if layout == "dzi" {
+			overlap, err = optionalInt(form, "overlap", validate.DefaultTileOverlap, 0, validate.MaxTileOverlap)
+			if err != nil {
+				return dto.Operation{}, err
+			}
+		}
+
+		format := strings.ToLower(form.FormValue("tile_format", validate.DefaultTileFormat))
+		if !validate.AllowedTileFormats[format] {
+			return dto.Operation{}, errors.New("invalid tile_format. Allowed: jpeg, png")
+		}
+
+		return dto.Operation{
+			Operation: "tiles",
+			Tiles: &dto.TileOptions{
+				Layout:   layout,
+				TileSize: tileSize,
+				Overlap:  overlap,
+				Format:   format,
+			},
+		}, nil
+	default:
+		return dto.Operation{}, errors.New("invalid operation. Allowed: resize, thumbnail, watermark, quantize, auto_enhance, trim, invisible_watermark, tiles, favicon, remove_background, transform, annotate")
+	}
+}
+
+func parseEnhanceOptions(form formValues) (dto.EnhanceOptions, error) {
+	equalize, err := optionalBool(form, "equalize", false)
+	if err != nil {
+		return dto.EnhanceOptions{}, err
+	}
+
+	strength, err := optionalFloat(form, "strength", validate.DefaultEnhanceStrength, 0, 1)
+	if err != nil {
+		return dto.EnhanceOptions{}, err
+	}
+
+	whiteBalance, err := optionalBool(form, "white_balance", true)
+	if err != nil {
+		return dto.EnhanceOptions{}, err
+	}
+
+	return dto.EnhanceOptions{
+		Equalize:     equalize,
+		Strength:     strength,
+		WhiteBalance: whiteBalance,
+	}, nil
+}
+
+func parseTransformOptions(form formValues) (dto.TransformOptions, error) {
+	matrix, err := optionalFloats(form, "matrix", validate.TransformMatrixLen)
+	if err != nil {
+		return dto.TransformOptions{}, err
+	}
+
+	coords, err := optionalFloats(form, "corners", validate.TransformCornersLen)
+	if err != nil {
+		return dto.TransformOptions{}, err
+	}
+
+	if (matrix == nil) == (coords == nil) {
+		return dto.TransformOptions{}, errors.New("exactly one of matrix or corners is required for transform")
+	}
+
+	// вырожденную матрицу отсекаем сразу, углы проверит обработчик
+	if matrix != nil && matrix[0]*matrix[4]-matrix[1]*matrix[3] == 0 {
+		return dto.TransformOptions{}, errors.New("matrix must be invertible")
+	}
+
+	var corners []dto.Point
+	for i := 0; i < len(coords); i += 2 {
+		corners = append(corners, dto.Point{X: coords[i], Y: coords[i+1]})
+	}
+
+	width, err := optionalInt(form, "width", 0, validate.MinResizeWidth, validate.MaxResizeWidth)
+	if err != nil {
+		return dto.TransformOptions{}, err
+	}
+
+	height, err := optionalInt(form, "height", 0, validate.MinResizeHeight, validate.MaxResizeHeight)
+	if err != nil {
+		return dto.TransformOptions{}, err
+	}
+
+	if width*height > validate.MaxTransformPixels {
+		return dto.TransformOptions{}, fmt.Errorf("transform output cant have more than %d pixels", validate.MaxTransformPixels)
+	}
+
+	interpolation := strings.ToLower(form.FormValue("interpolation", validate.DefaultInterpolation))
+	if !validate.AllowedInterpolations[interpolation] {
+		return dto.TransformOptions{}, errors.New("invalid interpolation. Allowed: bilinear, bicubic")
+	}
+
+	fill := form.FormValue("fill")
+	if fill != "" && !validate.HexColor(fill) {
+		return dto.TransformOptions{}, errors.New("fill must be a color in #RRGGBB or #RRGGBBAA format")
+	}
+
+	return dto.TransformOptions{
+		Matrix:        matrix,
+		Corners:       corners,
+		Width:         width,
+		Height:        height,
+		Interpolation: interpolation,
+		Fill:          fill,
+	}, nil
+}
+
+// parseShapes разбирает JSON-список примитивов и подставляет значения по умолчанию.
+func parseShapes(form formValues) ([]dto.Shape, error) {
+	str := form.FormValue("shapes")
+	if str == "" {
+		return nil, errors.New("shapes is required for annotate")
+	}
+
+	var shapes []dto.Shape
+	if err := json.Unmarshal([]byte(str), &shapes); err != nil {
+		return nil, errors.New("shapes must be a JSON array of objects")
+	}
+
+	if len(shapes) == 0 || len(shapes) > validate.MaxAnnotateShapes {
+		return nil, fmt.Errorf("shapes must contain from 1 to %d items", validate.MaxAnnotateShapes)
+	}
+
+	for i := range shapes {
+		s := &shapes[i]
+
+		s.Type = strings.ToLower(s.Type)
+		if !validate.AllowedShapeTypes[s.Type] {
+			return nil, fmt.Errorf("shapes[%d]: invalid type. Allowed: rect, ellipse, line, arrow, text", i)
+		}
+
+		for _, v := range []float64{s.X, s.Y, s.Width, s.Height, s.X2, s.Y2} {
+			if math.Abs(v) > validate.MaxShapeCoordinate {
+				return nil, fmt.Errorf("shapes[%d]: coordinates must be between %g and %g", i, -validate.MaxShapeCoordinate, validate.MaxShapeCoordinate)
+			}
+		}
+
+		switch s.Type {
+		case "rect", "ellipse":
+			if s.Width <= 0 || s.Height <= 0 {
+				return nil, fmt.Errorf("shapes[%d]: width and height must be positive", i)
+			}
+		case "line", "arrow":
+			if s.X == s.X2 && s.Y == s.Y2 {
+				return nil, fmt.Errorf("shapes[%d]: start and end must differ", i)
+			}
+		case "text":
+			if s.Text == "" || len(s.Text) > validate.MaxLabelLen {
+				return nil, fmt.Errorf("shapes[%d]: text length must be between 1 and %d", i, validate.MaxLabelLen)
+			}
+		}
+
+		if s.Color == "" {
+			s.Color = validate.DefaultAnnotateColor
+		}
+		if !validate.HexColor(s.Color) {
+			return nil, fmt.Errorf("shapes[%d]: color must be in #RRGGBB or #RRGGBBAA format", i)
+		}
+		if s.Fill != "" && !validate.HexColor(s.Fill) {
+			return nil, fmt.Errorf("shapes[%d]: fill must be in #RRGGBB or #RRGGBBAA format", i)
+		}
+
+		if s.StrokeWidth == 0 {
+			s.StrokeWidth = validate.DefaultStrokeWidth
+		}
+		if s.StrokeWidth < 0 || s.StrokeWidth > validate.MaxStrokeWidth {
+			return nil, fmt.Errorf("shapes[%d]: stroke_width must be between 0 and %g", i, validate.MaxStrokeWidth)
+		}
+
+		if s.FontSize == 0 {
+			s.FontSize = validate.DefaultFontSize
+		}
+		if s.FontSize < validate.MinFontSize || s.FontSize > validate.MaxFontSize {
+			return nil, fmt.Errorf("shapes[%d]: font_size must be between %g and %g", i, validate.MinFontSize, validate.MaxFontSize)
+		}
+	}
+
+	return shapes, nil
+}
+
+// parseOutputMetadata собирает поля EXIF/XMP запроса. Без полей - nil, остаются значения по умолчанию.
+func parseOutputMetadata(form formValues) (*dto.OutputMetadata, error) {
+	m := dto.OutputMetadata{
+		Copyright:   form.FormValue("copyright"),
+		Artist:      form.FormValue("artist"),
+		Description: form.FormValue("description"),
+		SourceURL:   form.FormValue("source_url"),
+	}
+
+	for key, value := range map[string]string{
+		"copyright":   m.Copyright,
+		"artist":      m.Artist,
+		"description": m.Description,
+		"source_url":  m.SourceURL,
+	} {
+		if len(value) > validate.MaxMetadataFieldLen {
+			return nil, fmt.Errorf("%s can't be longer than %d bytes", key, validate.MaxMetadataFieldLen)
+		}
+	}
+
+	if m.SourceURL != "" {
+		u, err := url.Parse(m.SourceURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errors.New("source_url must be an http or https URL")
+		}
+	}
+
+	if str := form.FormValue("custom_metadata"); str != "" {
+		if err := json.Unmarshal([]byte(str), &m.Custom); err != nil {
+			return nil, errors.New("custom_metadata must be a JSON object with string values")
+		}
+		if len(m.Custom) > validate.MaxCustomMetadata {
+			return nil, fmt.Errorf("custom_metadata can't have more than %d keys", validate.MaxCustomMetadata)
+		}
+		for key, value := range m.Custom {
+			if !validate.MetadataKey(key) {
+				return nil, fmt.Errorf("invalid custom_metadata key: %s", key)
+			}
+			if len(value) > validate.MaxCustomMetadataValue {
+				return nil, fmt.Errorf("custom_metadata values can't be longer than %d bytes", validate.MaxCustomMetadataValue)
+			}
+		}
+	}
+
+	if m.Copyright == "" && m.Artist == "" && m.Description == "" && m.SourceURL == "" && len(m.Custom) == 0 {
+		return nil, nil
+	}
+
+	return &m, nil
+}
+
+func parseRasterizeOptions(form formValues) (dto.RasterizeOptions, error) {
+	width, err := optionalInt(form, "svg_width", 0, validate.MinResizeWidth, validate.MaxResizeWidth)
+	if err != nil {
+		return dto.RasterizeOptions{}, err
+	}
+
+	height, err := optionalInt(form, "svg_height", 0, validate.MinResizeHeight, validate.MaxResizeHeight)
+	if err != nil {
+		return dto.RasterizeOptions{}, err
+	}
+
+	dpi, err := optionalFloat(form, "dpi", 0, validate.MinSVGDPI, validate.MaxSVGDPI)
+	if err != nil {
+		return dto.RasterizeOptions{}, err
+	}
+
+	return dto.RasterizeOptions{
+		Width:  width,
+		Height: height,
+		DPI:    dpi,
+	}, nil
+}
+
+func requiredInt(form formValues, key, operation string, lo, hi int) (int, error) {
+	str := form.FormValue(key)
+	if str == "" {
+		return 0, fmt.Errorf("%s is required for %s", key, operation)
+	}
+
+	return parseInt(key, str, lo, hi)
+}
+
+func optionalInt(form formValues, key string, def, lo, hi int) (int, error) {
+	str := form.FormValue(key)
+	if str == "" {
+		return def, nil
+	}
+
+	return parseInt(key, str, lo, hi)
+}
+
+func parseInt(key, str string, lo, hi int) (int, error) {
+	v, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", key)
+	}
+	if v < lo || v > hi {
+		return 0, fmt.Errorf("%s must be between %d and %d", key, lo, hi)
+	}
+
+	return v, nil
+}
+
+func optionalFloat(form formValues, key string, def, lo, hi float64) (float64, error) {
+	str := form.FormValue(key)
+	if str == "" {
+		return def, nil
+	}
+
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", key)
+	}
+	if v < lo || v > hi {
+		return 0, fmt.Errorf("%s must be between %g and %g", key, lo, hi)
+	}
+
+	return v, nil
+}
+
+// optionalFloats разбирает список из n чисел через запятую. Пустое поле - nil.
+func optionalFloats(form formValues, key string, n int) ([]float64, error) {
+	str := form.FormValue(key)
+	if str == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(str, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("%s must be %d comma-separated numbers", key, n)
+	}
+
+	values := make([]float64, n)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("%s must be %d comma-separated numbers", key, n)
+		}
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+func optionalBool(form formValues, key string, def bool) (bool, error) {
+	str := form.FormValue(key)
+	if str == "" {
+		return def, nil
+	}
+
+	v, err := strconv.ParseBool(str)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", key)
+	}
+
+	return v, nil
+}
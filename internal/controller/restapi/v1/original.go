@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary 	Get original image
+// @Description Downloads the uploaded original from S3 regardless of processing status. Images assembled from other images(collage, sprite, template) have no original
+// @Tags 		images
+// @Produce 	image/jpeg,image/png,image/gif,image/bmp,image/tiff,image/svg+xml
+// @Param 		id path string true "Image ID(uuid)"
+// @Success 	200 {file} 	binary
+// @Failure 	400 {object} response.Error "Invalid ID"
+// @Failure 	404 {object} response.Error "Image or original not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/image/{id}/original [get]
+func (r *V1) getOriginalImage(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	image, err := r.img.GetImage(ctx.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "image not found")
+		}
+		r.logger.Error(err, "restapi - v1 - getOriginalImage")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	// собранные из других изображений оригинала не имеют
+	if image.OriginalKey == "" {
+		return errorResponse(ctx, http.StatusNotFound, "image has no original")
+	}
+
+	body, err := r.img.DownloadImage(ctx.UserContext(), image.OriginalKey)
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - getOriginalImage")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	ctx.Set(fiber.HeaderContentType, image.ContentType)
+	// FormatMediaType экранирует кавычки и кодирует не-ASCII имена по RFC 2231
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": image.OriginalName})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	ctx.Set(fiber.HeaderContentDisposition, disposition)
+
+	return ctx.SendStream(body)
+}
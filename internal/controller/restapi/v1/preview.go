@@ -0,0 +1,194 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary  	Preview operation
+// @Description Runs the operation inline on a small image and returns the result. Nothing is stored. Accepts the same operation fields as /upload except tiles and favicon
+// @Tags 		images
+// @Accept 		mpfd
+// @Produce 	image/jpeg,image/png,image/gif,image/bmp,image/tiff
+// @Param 		file 	  formData file   true  "Image file(jpg, png, gif, bmp, tiff), limited by PREVIEW_MAX_FILE_SIZE and PREVIEW_MAX_PIXELS"
+// @Param 		operation formData string true  "Operation" Enums(resize, thumbnail, watermark, quantize, auto_enhance, trim, invisible_watermark, remove_background, transform, annotate)
+// @Param 		text 	  formData string false "Text(required for watermark operation)"
+// @Param 		width 	  formData int    false "Width(required for resize operation, output width for transform)"
+// @Param 		height 	  formData int 	  false "Height(required for resize operation, output height for transform)"
+// @Success 	200 {file} 	binary
+// @Failure 	400 {object} response.Error "Empty file or wrong parameters"
+// @Failure 	413 {object} response.Error "File or output too large"
+// @Failure 	415 {object} response.Error "Unsupported format"
+// @Failure 	422 {object} response.Error "Image can't be processed"
+// @Failure 	429 {object} response.Error "Too many previews in progress"
+// @Failure 	500 {object} response.Error "Internal"
+// @Failure 	503 {object} response.Error "Processing timed out"
+// @Router 		/v1/preview [post]
+func (r *V1) previewImage(ctx *fiber.Ctx) error {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "file is required")
+	}
+
+	// 1. валидация размера файла
+	if file.Size == 0 {
+		return errorResponse(ctx, http.StatusBadRequest, "file is empty")
+	}
+
+	if file.Size > r.preview.MaxFileSize {
+		return errorResponse(ctx, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("file size cant be more than %d bytes for preview", r.preview.MaxFileSize))
+	}
+
+	// 2. открытие файла
+	fileReader, err := file.Open()
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - previewImage")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "problems with opening the file")
+	}
+	defer fileReader.Close()
+
+	// 3. формат по содержимому; размер растра SVG заранее не известен, поэтому только растровые форматы
+	contentType, err := sniffContentType(fileReader)
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - previewImage")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "problems with reading the file")
+	}
+	if !validate.AllowedContentTypes[contentType] || contentType == validate.SVGContentType {
+		return errorResponse(ctx, http.StatusUnsupportedMediaType, "unsupported file type. Allowed: jpeg, png, gif, bmp, tiff")
+	}
+	if !validate.DeclaredTypeMatches(file.Header.Get("Content-Type"), contentType) {
+		return errorResponse(ctx, http.StatusUnsupportedMediaType, "file content doesn't match its content type")
+	}
+
+	data, err := io.ReadAll(fileReader)
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - previewImage")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "problems with reading the file")
+	}
+
+	// 4. валидация числа пикселей по заголовку, до декодирования
+	width, height, err := validate.ImageSize(data)
+	if err != nil {
+		return errorResponse(ctx, http.StatusUnprocessableEntity, "image can't be decoded")
+	}
+	if width*height > r.preview.MaxPixels {
+		return errorResponse(ctx, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("image cant have more than %d pixels for preview", r.preview.MaxPixels))
+	}
+
+	// 5. валидация операции
+	op, err := parseOperation(ctx)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	if !validate.AllowedPreviewOperations[op.Operation] {
+		return errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("operation %s is not available for preview", op.Operation))
+	}
+	if op.Width != nil && op.Height != nil && *op.Width**op.Height > r.preview.MaxPixels {
+		return errorResponse(ctx, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("output cant have more than %d pixels for preview", r.preview.MaxPixels))
+	}
+	if op.Transform != nil {
+		outWidth, outHeight, err := r.prc.TransformSize(ctx.UserContext(), width, height, *op.Transform)
+		if err != nil {
+			return errorResponse(ctx, http.StatusBadRequest, "transform is degenerate or its output is too large")
+		}
+		if outWidth*outHeight > r.preview.MaxPixels {
+			return errorResponse(ctx, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("output cant have more than %d pixels for preview", r.preview.MaxPixels))
+		}
+	}
+	if op.Mark != nil && op.Mark.Payload == "" {
+		mark := *op.Mark
+		mark.Payload = validate.PreviewMarkPayload
+		op.Mark = &mark
+	}
+
+	// 6. ждем свободный слот, чтобы превью не съели CPU у фоновой обработки
+	release, uerr := r.acquireSlot(ctx)
+	if uerr != nil {
+		return errorResponse(ctx, uerr.code, uerr.msg)
+	}
+	defer release()
+
+	// 7. обработка
+	cpuCtx, cpuCancel := context.WithTimeout(ctx.UserContext(), r.preview.Timeout)
+	defer cpuCancel()
+	result, err := r.prc.Process(cpuCtx, contentType, dto.Task{
+		Data:      data,
+		Operation: op.Operation,
+		Width:     op.Width,
+		Height:    op.Height,
+		Text:      op.Text,
+		Quantize:  op.Quantize,
+		Enhance:   op.Enhance,
+		Trim:      op.Trim,
+		Mark:      op.Mark,
+		Keying:    op.Keying,
+		Transform: op.Transform,
+		Annotate:  op.Annotate,
+		Output:    op.Output,
+	})
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return errorResponse(ctx, http.StatusServiceUnavailable, "preview processing timed out")
+		}
+		r.logger.Error(err, "restapi - v1 - previewImage")
+
+		return errorResponse(ctx, http.StatusUnprocessableEntity, "image can't be processed")
+	}
+
+	ctx.Set(fiber.HeaderContentType, result.ContentType)
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+
+	return ctx.Status(http.StatusOK).Send(result.Data)
+}
+
+// acquireSlot ждет свободный слот синхронной обработки: превью, сравнение и поиск знака
+// делят один лимит, чтобы не съесть CPU у фоновой обработки. Слот освобождает release.
+func (r *V1) acquireSlot(ctx *fiber.Ctx) (func(), *uploadError) {
+	wait := time.NewTimer(r.preview.WaitTimeout)
+	defer wait.Stop()
+
+	select {
+	case r.previewSlots <- struct{}{}:
+		return func() { <-r.previewSlots }, nil
+	case <-wait.C:
+		return nil, &uploadError{code: http.StatusTooManyRequests, msg: "too many inline operations in progress, try again later"}
+	case <-ctx.UserContext().Done():
+		return nil, &uploadError{code: http.StatusServiceUnavailable, msg: "request canceled"}
+	}
+}
+
+// checkPixels проверяет число пикселей растра по заголовку, до декодирования.
+// SVG заголовка с размером не имеет, его растр ограничен при растеризации.
+func checkPixels(data []byte, maxPixels int) *uploadError {
+	if validate.SniffContentType(data[:min(len(data), validate.SniffLen)]) == validate.SVGContentType {
+		return nil
+	}
+
+	width, height, err := validate.ImageSize(data)
+	if err != nil {
+		return &uploadError{code: http.StatusUnprocessableEntity, msg: "image can't be decoded"}
+	}
+	if width*height > maxPixels {
+		return &uploadError{
+			code: http.StatusRequestEntityTooLarge,
+			msg:  fmt.Sprintf("image cant have more than %d pixels", maxPixels),
+		}
+	}
+
+	return nil
+}
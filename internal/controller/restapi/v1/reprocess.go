@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary  	Reprocess image
+// @Description Queues a new operation for the already uploaded original without re-uploading it. The result replaces the current processed image. Accepts the same operation fields as /upload
+// @Tags 		images
+// @Accept 		mpfd
+// @Produce 	json
+// @Param 		id 		  path 	   string true  "Image ID(uuid)"
+// @Param 		operation formData string true  "Operation" Enums(resize, thumbnail, watermark, quantize, auto_enhance, trim, invisible_watermark, tiles, favicon, remove_background, transform, annotate)
+// @Param 		text 	  formData string false "Text(required for watermark operation)"
+// @Param 		width 	  formData int    false "Width(required for resize operation, output width for transform)"
+// @Param 		height 	  formData int 	  false "Height(required for resize operation, output height for transform)"
+// @Success 	202 {object} response.ProcessImage
+// @Failure 	400 {object} response.Error "Invalid ID or wrong parameters"
+// @Failure 	404 {object} response.Error "Image not found"
+// @Failure 	409 {object} response.Error "Image is already being processed"
+// @Failure 	422 {object} response.Error "Image has no original(collage, sprite, template)"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/image/{id}/process [post]
+func (r *V1) reprocessImage(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	// 1. валидация операции
+	op, err := parseOperation(ctx)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	// параметры растеризации нужны только векторному оригиналу, остальные их не читают
+	opts, err := parseRasterizeOptions(ctx)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	op.Rasterize = &opts
+
+	// 2. ставим в очередь
+	image, err := r.img.ReprocessImage(ctx.UserContext(), id, op)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrRecordNotFound):
+			return errorResponse(ctx, http.StatusNotFound, "image not found")
+		case errors.Is(err, errs.ErrImageBusy):
+			return errorResponse(ctx, http.StatusConflict, "image is already being processed, try again later")
+		case errors.Is(err, errs.ErrNoOriginal):
+			return errorResponse(ctx, http.StatusUnprocessableEntity, "image has no original to reprocess")
+		}
+		r.logger.Error(err, "restapi - v1 - reprocessImage")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	// 3. ответ
+	ctx.Set(fiber.HeaderLocation, fmt.Sprintf("/v1/image/%s/status", id))
+
+	return ctx.Status(http.StatusAccepted).JSON(response.ProcessImage{
+		ImageID:      image.ID.String(),
+		OriginalName: image.OriginalName,
+		Size:         int(image.Size),
+		ContentType:  image.ContentType,
+		Status:       string(image.Status),
+		Operation:    op.Operation,
+		CreatedAt:    image.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	})
+}
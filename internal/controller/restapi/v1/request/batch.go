@@ -0,0 +1,5 @@
+package request
+
+type BatchDelete struct {
+	ImageIDs []string `json:"image_ids" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6,9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d"`
+}
@@ -0,0 +1,13 @@
+package request
+
+type Collage struct {
+	ImageIDs   []string `json:"image_ids" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6,9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d"`
+	Rows       int      `json:"rows" example:"1"`
+	Columns    int      `json:"columns" example:"2"`
+	CellWidth  int      `json:"cell_width" example:"400"`
+	CellHeight int      `json:"cell_height" example:"300"`
+	Gap        int      `json:"gap" example:"10"`
+	Background string   `json:"background" example:"#ffffff"`
+	Fit        string   `json:"fit" example:"fill" enums:"fill,fit,stretch"`
+	Format     string   `json:"format" example:"jpeg" enums:"jpeg,png"`
+}
@@ -0,0 +1,37 @@
+package request
+
+type Template struct {
+	Name       string          `json:"name" example:"product-card"`
+	Width      int             `json:"width" example:"1200"`
+	Height     int             `json:"height" example:"630"`
+	Background string          `json:"background" example:"#ffffff"`
+	Layers     []TemplateLayer `json:"layers"`
+}
+
+type TemplateLayer struct {
+	Type    string `json:"type" example:"slot" enums:"base,slot,text"`
+	Name    string `json:"name" example:"product"`
+	ImageID string `json:"image_id" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
+
+	X      int `json:"x" example:"60"`
+	Y      int `json:"y" example:"60"`
+	Width  int `json:"width" example:"510"`
+	Height int `json:"height" example:"510"`
+
+	Fit string `json:"fit" example:"fill" enums:"fill,fit,stretch"`
+
+	Text       string  `json:"text" example:"New arrival"`
+	Font       string  `json:"font" example:"bold" enums:"regular,bold,italic,mono"`
+	FontSize   float64 `json:"font_size" example:"56"`
+	LineHeight float64 `json:"line_height" example:"1.2"`
+	Color      string  `json:"color" example:"#111111"`
+	Align      string  `json:"align" example:"left" enums:"left,center,right"`
+	VAlign     string  `json:"valign" example:"middle" enums:"top,middle,bottom"`
+	MaxLines   int     `json:"max_lines" example:"3"`
+}
+
+type RenderTemplate struct {
+	Images map[string]string `json:"images"` // имя слота -> id изображения
+	Texts  map[string]string `json:"texts"`  // имя текстового слоя -> текст
+	Format string            `json:"format" example:"png" enums:"jpeg,png"`
+}
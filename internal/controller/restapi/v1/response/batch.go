@@ -0,0 +1,28 @@
+package response
+
+type BatchUploadItem struct {
+	Index    int           `json:"index"`
+	FileName string        `json:"file_name"`
+	Code     int           `json:"code"` // HTTP-код, который вернул бы одиночный /upload
+	Error    string        `json:"error,omitempty"`
+	Image    *ProcessImage `json:"image,omitempty"`
+}
+
+type BatchUpload struct {
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchUploadItem `json:"results"`
+	Error     string            `json:"error,omitempty"` // почему пакет прерван: файлы после ошибки не прочитаны и не попали в results
+}
+
+type BatchDeleteItem struct {
+	ImageID string `json:"image_id"`
+	Code    int    `json:"code"`
+	Error   string `json:"error,omitempty"`
+}
+
+type BatchDelete struct {
+	Deleted int               `json:"deleted"`
+	Failed  int               `json:"failed"`
+	Results []BatchDeleteItem `json:"results"`
+}
@@ -0,0 +1,8 @@
+package response
+
+type Comparison struct {
+	SSIM   float64 `json:"ssim" example:"0.9731"`
+	PSNR   float64 `json:"psnr" example:"38.42"`
+	Width  int     `json:"width" example:"800"`
+	Height int     `json:"height" example:"600"`
+}
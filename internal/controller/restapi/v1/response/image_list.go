@@ -0,0 +1,18 @@
+package response
+
+import "github.com/andreyxaxa/Image-Processor/internal/entity"
+
+type ImageListItem struct {
+	ImageID      string          `json:"image_id"`
+	OriginalName string          `json:"original_name"`
+	Size         int             `json:"size"`
+	ContentType  string          `json:"content_type"`
+	Status       string          `json:"status"`
+	Quality      *entity.Quality `json:"quality,omitempty"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+type ImageList struct {
+	Images     []ImageListItem `json:"images"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,18 @@
+package response
+
+import "github.com/andreyxaxa/Image-Processor/internal/entity"
+
+type ImageStatus struct {
+	ImageID              string          `json:"image_id"`
+	OriginalName         string          `json:"original_name"`
+	Size                 int             `json:"size"`
+	ContentType          string          `json:"content_type"`
+	ProcessedContentType string          `json:"processed_content_type,omitempty"`
+	Status               string          `json:"status"`
+	Operation            string          `json:"operation,omitempty"`
+	HasProcessed         bool            `json:"has_processed"`
+	FailureReason        string          `json:"failure_reason,omitempty"`
+	Metadata             entity.Metadata `json:"metadata"`
+	CreatedAt            string          `json:"created_at"`
+	ProcessedAt          string          `json:"processed_at,omitempty"`
+}
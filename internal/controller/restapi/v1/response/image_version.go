@@ -0,0 +1,18 @@
+package response
+
+import "encoding/json"
+
+type ImageVersion struct {
+	Version     int             `json:"version"`
+	ContentType string          `json:"content_type"`
+	Size        int             `json:"size"`
+	Operation   string          `json:"operation"`
+	Spec        json.RawMessage `json:"spec" swaggertype:"object"`
+	Current     bool            `json:"current"`
+	CreatedAt   string          `json:"created_at"`
+}
+
+type ImageVersions struct {
+	ImageID  string         `json:"image_id"`
+	Versions []ImageVersion `json:"versions"`
+}
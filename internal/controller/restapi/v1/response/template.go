@@ -0,0 +1,14 @@
+package response
+
+import "github.com/andreyxaxa/Image-Processor/internal/entity"
+
+type Template struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	Width      int                    `json:"width"`
+	Height     int                    `json:"height"`
+	Background string                 `json:"background"`
+	Layers     []entity.TemplateLayer `json:"layers"`
+	CreatedAt  string                 `json:"created_at"`
+	UpdatedAt  string                 `json:"updated_at"`
+}
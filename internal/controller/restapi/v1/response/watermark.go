@@ -0,0 +1,7 @@
+package response
+
+type WatermarkDetection struct {
+	Found      bool    `json:"found" example:"true"`
+	Payload    string  `json:"payload,omitempty" example:"6f1c2f0e-8a4b-4c55-9a53-0d7f0f3b9c11"`
+	Confidence float64 `json:"confidence,omitempty" example:"0.91"`
+}
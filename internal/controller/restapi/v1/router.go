@@ -1,19 +1,56 @@
 package v1
 
 import (
+	"github.com/andreyxaxa/Image-Processor/config"
 	"github.com/andreyxaxa/Image-Processor/internal/usecase"
 	"github.com/andreyxaxa/Image-Processor/pkg/logger"
 	"github.com/gofiber/fiber/v2"
 )
 
-func NewImageRoutes(apiV1Group fiber.Router, img usecase.ImageUseCase, l logger.Interface) {
-	r := &V1{img: img, logger: l}
+func NewImageRoutes(
+	apiV1Group fiber.Router,
+	img usecase.ImageUseCase,
+	prc usecase.ImageProcessorUseCase,
+	preview config.Preview,
+	l logger.Interface,
+) {
+	r := &V1{
+		img:          img,
+		prc:          prc,
+		logger:       l,
+		preview:      preview,
+		previewSlots: make(chan struct{}, max(preview.Concurrency, 1)),
+	}
 
 	{
 		// API
 		apiV1Group.Post("/upload", r.processImage)
+		apiV1Group.Post("/upload/batch", r.batchUpload)
+		apiV1Group.Post("/upload/url", r.uploadFromURL)
+		apiV1Group.Post("/collage", r.createCollage)
+		apiV1Group.Post("/sprite", r.createSprite)
+		apiV1Group.Post("/preview", r.previewImage)
+		apiV1Group.Get("/images", r.listImages)
+		apiV1Group.Post("/images/delete", r.batchDelete)
 		apiV1Group.Get("/image/:id", r.getProcessedImage)
+		apiV1Group.Get("/image/:id/status", r.getImageStatus)
+		apiV1Group.Get("/image/:id/original", r.getOriginalImage)
+		apiV1Group.Post("/image/:id/process", r.reprocessImage)
+		apiV1Group.Get("/image/:id/versions", r.listVersions)
+		apiV1Group.Get("/image/:id/versions/:version", r.getVersion)
+		apiV1Group.Post("/image/:id/versions/:version/current", r.setCurrentVersion)
 		apiV1Group.Delete("/image/:id", r.deleteImage)
+		apiV1Group.Get("/image/:id/compare", r.compareWithOriginal)
+		apiV1Group.Get("/image/:id/tiles/*", r.getTile)
+		apiV1Group.Get("/image/:id/files/:name", r.getFile)
+		apiV1Group.Get("/compare", r.compareImages)
+		apiV1Group.Post("/watermark/detect", r.detectWatermark)
+		apiV1Group.Post("/templates", r.createTemplate)
+		apiV1Group.Get("/templates", r.listTemplates)
+		apiV1Group.Get("/template/:id", r.getTemplate)
+		apiV1Group.Put("/template/:id", r.updateTemplate)
+		apiV1Group.Delete("/template/:id", r.deleteTemplate)
+		apiV1Group.Post("/template/:id/render", r.renderTemplate)
 
 		// UI
 		apiV1Group.Get("/", r.showUI)
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/request"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary  	Create sprite sheet
+// @Description Packs existing images into one PNG sprite sheet. Frame coordinates are stored in sprite.json manifest, available at /v1/image/{id}/files/sprite.json after processing
+// @Tags 		images
+// @Accept 		json
+// @Produce 	json
+// @Param 		request body request.Sprite true "Source image IDs and packing options"
+// @Success 	201 {object} response.ProcessImage
+// @Failure 	400 {object} response.Error "Wrong parameters"
+// @Failure 	404 {object} response.Error "Source image not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/sprite [post]
+func (r *V1) createSprite(ctx *fiber.Ctx) error {
+	var body request.Sprite
+	if err := ctx.BodyParser(&body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid request body")
+	}
+
+	// 1. валидация id исходников
+	IDs, err := parseImageIDs(body.ImageIDs)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	if len(IDs) > validate.MaxSpriteImages {
+		return errorResponse(ctx, http.StatusBadRequest,
+			fmt.Sprintf("sprite can't contain more than %d images", validate.MaxSpriteImages))
+	}
+
+	// 2. валидация упаковки
+	padding := validate.DefaultSpritePadding
+	if body.Padding != nil {
+		padding = *body.Padding
+	}
+	if padding < 0 || padding > validate.MaxSpritePadding {
+		return errorResponse(ctx, http.StatusBadRequest,
+			fmt.Sprintf("padding must be between 0 and %d", validate.MaxSpritePadding))
+	}
+
+	if body.IconSize != 0 && (body.IconSize < validate.MinSpriteIconSize || body.IconSize > validate.MaxSpriteIconSize) {
+		return errorResponse(ctx, http.StatusBadRequest,
+			fmt.Sprintf("icon_size must be 0 or between %d and %d", validate.MinSpriteIconSize, validate.MaxSpriteIconSize))
+	}
+
+	// 3. создаем
+	image, err := r.img.CreateSprite(ctx.UserContext(), IDs, dto.SpriteOptions{
+		Padding:  padding,
+		IconSize: body.IconSize,
+	})
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "source image not found")
+		}
+		r.logger.Error(err, "restapi - v1 - createSprite")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	// 4. ответ
+	resp := response.ProcessImage{
+		ImageID:      image.ID.String(),
+		OriginalName: image.OriginalName,
+		Size:         int(image.Size),
+		ContentType:  image.ContentType,
+		Status:       string(image.Status),
+		Operation:    "sprite",
+		CreatedAt:    image.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(resp)
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// сколько секунд клиенту ждать перед повторным опросом изображения в очереди
+const pendingRetryAfter = "2"
+
+// @Summary 	Get image status
+// @Description Returns image metadata: status, operation, timestamps, whether a processed result exists and why processing failed
+// @Tags 		images
+// @Produce 	json
+// @Param 		id path string true "Image ID(uuid)"
+// @Success 	200 {object} response.ImageStatus
+// @Failure 	400 {object} response.Error "Invalid ID"
+// @Failure 	404 {object} response.Error "Image not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/image/{id}/status [get]
+func (r *V1) getImageStatus(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	image, err := r.img.GetImage(ctx.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "image not found")
+		}
+		r.logger.Error(err, "restapi - v1 - getImageStatus")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	return ctx.Status(http.StatusOK).JSON(imageStatusResponse(image))
+}
+
+func imageStatusResponse(image *entity.Image) response.ImageStatus {
+	resp := response.ImageStatus{
+		ImageID:      image.ID.String(),
+		OriginalName: image.OriginalName,
+		Size:         int(image.Size),
+		ContentType:  image.ContentType,
+		Status:       string(image.Status),
+		Operation:    image.Operation,
+		HasProcessed: image.ProcessedKey != nil,
+		Metadata:     image.Metadata,
+		CreatedAt:    image.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if image.ProcessedContentType != nil {
+		resp.ProcessedContentType = *image.ProcessedContentType
+	}
+	if image.FailureReason != nil {
+		resp.FailureReason = *image.FailureReason
+	}
+	if image.ProcessedAt != nil {
+		resp.ProcessedAt = image.ProcessedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return resp
+}
@@ -0,0 +1,446 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/request"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary  	Create template
+// @Description Stores a card template: canvas and layers drawn in order. base layers are fixed images, slot layers get images and text layers get texts at render time
+// @Tags 		templates
+// @Accept 		json
+// @Produce 	json
+// @Param 		request body request.Template true "Template"
+// @Success 	201 {object} response.Template
+// @Failure 	400 {object} response.Error "Wrong parameters"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/templates [post]
+func (r *V1) createTemplate(ctx *fiber.Ctx) error {
+	var body request.Template
+	if err := ctx.BodyParser(&body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid request body")
+	}
+
+	template, err := parseTemplate(body)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	if err := r.img.CreateTemplate(ctx.UserContext(), template); err != nil {
+		r.logger.Error(err, "restapi - v1 - createTemplate")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(templateResponse(template))
+}
+
+// @Summary  	List templates
+// @Tags 		templates
+// @Produce 	json
+// @Success 	200 {array} response.Template
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/templates [get]
+func (r *V1) listTemplates(ctx *fiber.Ctx) error {
+	templates, err := r.img.ListTemplates(ctx.UserContext())
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - listTemplates")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := make([]response.Template, 0, len(templates))
+	for _, t := range templates {
+		resp = append(resp, templateResponse(t))
+	}
+
+	return ctx.JSON(resp)
+}
+
+// @Summary  	Get template
+// @Tags 		templates
+// @Produce 	json
+// @Param 		id path string true "Template ID(uuid)"
+// @Success 	200 {object} response.Template
+// @Failure 	400 {object} response.Error "Invalid ID"
+// @Failure 	404 {object} response.Error "Template not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/template/{id} [get]
+func (r *V1) getTemplate(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	template, err := r.img.GetTemplate(ctx.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "template not found")
+		}
+		r.logger.Error(err, "restapi - v1 - getTemplate")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	return ctx.JSON(templateResponse(template))
+}
+
+// @Summary  	Update template
+// @Description Replaces the template entirely
+// @Tags 		templates
+// @Accept 		json
+// @Produce 	json
+// @Param 		id 		path string 		  true "Template ID(uuid)"
+// @Param 		request body request.Template true "Template"
+// @Success 	200 {object} response.Template
+// @Failure 	400 {object} response.Error "Wrong parameters"
+// @Failure 	404 {object} response.Error "Template not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/template/{id} [put]
+func (r *V1) updateTemplate(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	var body request.Template
+	if err := ctx.BodyParser(&body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid request body")
+	}
+
+	template, err := parseTemplate(body)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+	template.ID = id
+
+	if err := r.img.UpdateTemplate(ctx.UserContext(), template); err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "template not found")
+		}
+		r.logger.Error(err, "restapi - v1 - updateTemplate")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	return ctx.JSON(templateResponse(template))
+}
+
+// @Summary  	Delete template
+// @Tags 		templates
+// @Param 		id path string true "Template ID(uuid)"
+// @Success 	204 "Deleted"
+// @Failure 	400 {object} response.Error "Invalid ID"
+// @Failure 	404 {object} response.Error "Template not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/template/{id} [delete]
+func (r *V1) deleteTemplate(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	if err := r.img.DeleteTemplate(ctx.UserContext(), id); err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "template not found")
+		}
+		r.logger.Error(err, "restapi - v1 - deleteTemplate")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	return ctx.SendStatus(http.StatusNoContent)
+}
+
+// @Summary  	Render template
+// @Description Renders the template with supplied slot images and texts. Processing goes through outbox -> kafka -> worker, result is a new image
+// @Tags 		templates
+// @Accept 		json
+// @Produce 	json
+// @Param 		id 		path string 				true "Template ID(uuid)"
+// @Param 		request body request.RenderTemplate true "Slot images and texts"
+// @Success 	201 {object} response.ProcessImage
+// @Failure 	400 {object} response.Error "Wrong parameters"
+// @Failure 	404 {object} response.Error "Template or image not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/template/{id}/render [post]
+func (r *V1) renderTemplate(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	var body request.RenderTemplate
+	if err := ctx.BodyParser(&body); err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid request body")
+	}
+
+	format := strings.ToLower(body.Format)
+	if format == "" {
+		format = validate.DefaultTemplateFormat
+	}
+	contentType, ok := validate.OutputFormats[format]
+	if !ok {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid format. Allowed: jpeg, png")
+	}
+
+	// 1. шаблон
+	template, err := r.img.GetTemplate(ctx.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "template not found")
+		}
+		r.logger.Error(err, "restapi - v1 - renderTemplate")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	// 2. сверяем значения со слоями: каждый слот заполнен, лишних имен нет
+	slots := make(map[string]bool)
+	texts := make(map[string]bool)
+	for _, layer := range template.Layers {
+		switch layer.Type {
+		case entity.LayerSlot:
+			slots[layer.Name] = true
+		case entity.LayerText:
+			texts[layer.Name] = true
+		}
+	}
+
+	images := make(map[string]uuid.UUID, len(body.Images))
+	for name, idStr := range body.Images {
+		if !slots[name] {
+			return errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("unknown slot: %s", name))
+		}
+		imageID, err := uuid.Parse(idStr)
+		if err != nil {
+			return errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("invalid id: %s", idStr))
+		}
+		images[name] = imageID
+	}
+	for name := range slots {
+		if _, ok := images[name]; !ok {
+			return errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("image for slot %s is required", name))
+		}
+	}
+
+	for name, text := range body.Texts {
+		if !texts[name] {
+			return errorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("unknown text: %s", name))
+		}
+		if len(text) > validate.MaxTemplateText {
+			return errorResponse(ctx, http.StatusBadRequest,
+				fmt.Sprintf("text can't be longer than %d bytes", validate.MaxTemplateText))
+		}
+	}
+
+	// 3. создаем
+	image, err := r.img.RenderTemplate(ctx.UserContext(), template, images, body.Texts, contentType)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "source image not found")
+		}
+		r.logger.Error(err, "restapi - v1 - renderTemplate")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	// 4. ответ
+	resp := response.ProcessImage{
+		ImageID:      image.ID.String(),
+		OriginalName: image.OriginalName,
+		Size:         int(image.Size),
+		ContentType:  image.ContentType,
+		Status:       string(image.Status),
+		Operation:    "template",
+		CreatedAt:    image.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(resp)
+}
+
+// parseTemplate валидирует шаблон и подставляет значения по умолчанию.
+// Текст ошибки можно отдавать клиенту как есть.
+func parseTemplate(body request.Template) (*entity.Template, error) {
+	if body.Name == "" || len(body.Name) > validate.MaxTemplateNameLen {
+		return nil, fmt.Errorf("name length must be between 1 and %d", validate.MaxTemplateNameLen)
+	}
+
+	if body.Width < validate.MinTemplateSide || body.Width > validate.MaxTemplateSide ||
+		body.Height < validate.MinTemplateSide || body.Height > validate.MaxTemplateSide {
+		return nil, fmt.Errorf("width and height must be between %d and %d", validate.MinTemplateSide, validate.MaxTemplateSide)
+	}
+
+	if body.Background == "" {
+		body.Background = validate.DefaultTemplateBackground
+	}
+	if !validate.HexColor(body.Background) {
+		return nil, errors.New("background must be a color in #RRGGBB or #RRGGBBAA format")
+	}
+
+	if len(body.Layers) == 0 || len(body.Layers) > validate.MaxTemplateLayers {
+		return nil, fmt.Errorf("layers must contain from 1 to %d items", validate.MaxTemplateLayers)
+	}
+
+	names := make(map[string]bool)
+	layers := make([]entity.TemplateLayer, 0, len(body.Layers))
+	for i, l := range body.Layers {
+		layer, err := parseTemplateLayer(l, body.Width, body.Height)
+		if err != nil {
+			return nil, fmt.Errorf("layers[%d]: %w", i, err)
+		}
+
+		if layer.Name != "" {
+			if names[layer.Name] {
+				return nil, fmt.Errorf("layers[%d]: duplicate name %s", i, layer.Name)
+			}
+			names[layer.Name] = true
+		}
+
+		layers = append(layers, layer)
+	}
+
+	return &entity.Template{
+		Name:       body.Name,
+		Width:      body.Width,
+		Height:     body.Height,
+		Background: body.Background,
+		Layers:     layers,
+	}, nil
+}
+
+func parseTemplateLayer(l request.TemplateLayer, canvasWidth, canvasHeight int) (entity.TemplateLayer, error) {
+	layer := entity.TemplateLayer{
+		Type:   strings.ToLower(l.Type),
+		X:      l.X,
+		Y:      l.Y,
+		Width:  l.Width,
+		Height: l.Height,
+	}
+
+	if !validate.AllowedLayerTypes[layer.Type] {
+		return entity.TemplateLayer{}, errors.New("invalid type. Allowed: base, slot, text")
+	}
+
+	// без размеров слой занимает весь холст
+	if layer.Width == 0 && layer.Height == 0 {
+		layer.X, layer.Y = 0, 0
+		layer.Width, layer.Height = canvasWidth, canvasHeight
+	}
+	if layer.Width < 1 || layer.Height < 1 || layer.Width > validate.MaxTemplateSide || layer.Height > validate.MaxTemplateSide {
+		return entity.TemplateLayer{}, fmt.Errorf("width and height must be between 1 and %d", validate.MaxTemplateSide)
+	}
+	if layer.X < -layer.Width || layer.Y < -layer.Height || layer.X > canvasWidth || layer.Y > canvasHeight {
+		return entity.TemplateLayer{}, errors.New("layer must overlap the canvas")
+	}
+
+	// имя нужно слотам и текстам, чтобы передать значения при рендере
+	if layer.Type != entity.LayerBase {
+		if !validate.LayerName.MatchString(l.Name) {
+			return entity.TemplateLayer{}, errors.New("name must match ^[a-z][a-z0-9_]{0,31}$")
+		}
+		layer.Name = l.Name
+	}
+
+	switch layer.Type {
+	case entity.LayerBase, entity.LayerSlot:
+		if layer.Type == entity.LayerBase {
+			id, err := uuid.Parse(l.ImageID)
+			if err != nil {
+				return entity.TemplateLayer{}, errors.New("image_id is required for base layer")
+			}
+			layer.ImageID = &id
+		}
+
+		layer.Fit = strings.ToLower(l.Fit)
+		if layer.Fit == "" {
+			layer.Fit = validate.DefaultCollageFit
+		}
+		if !validate.AllowedCollageFits[layer.Fit] {
+			return entity.TemplateLayer{}, errors.New("invalid fit. Allowed: fill, fit, stretch")
+		}
+	case entity.LayerText:
+		if len(l.Text) > validate.MaxTemplateText {
+			return entity.TemplateLayer{}, fmt.Errorf("text can't be longer than %d bytes", validate.MaxTemplateText)
+		}
+		layer.Text = l.Text
+
+		layer.Font = strings.ToLower(l.Font)
+		if layer.Font == "" {
+			layer.Font = validate.DefaultTemplateFont
+		}
+		if !validate.AllowedFonts[layer.Font] {
+			return entity.TemplateLayer{}, errors.New("invalid font. Allowed: regular, bold, italic, mono")
+		}
+
+		layer.FontSize = l.FontSize
+		if layer.FontSize == 0 {
+			layer.FontSize = validate.DefaultTemplateFontSize
+		}
+		if layer.FontSize < validate.MinFontSize || layer.FontSize > validate.MaxFontSize {
+			return entity.TemplateLayer{}, fmt.Errorf("font_size must be between %g and %g", validate.MinFontSize, validate.MaxFontSize)
+		}
+
+		layer.LineHeight = l.LineHeight
+		if layer.LineHeight == 0 {
+			layer.LineHeight = validate.DefaultLineHeight
+		}
+		if layer.LineHeight < validate.MinLineHeight || layer.LineHeight > validate.MaxLineHeight {
+			return entity.TemplateLayer{}, fmt.Errorf("line_height must be between %g and %g", validate.MinLineHeight, validate.MaxLineHeight)
+		}
+
+		layer.Color = l.Color
+		if layer.Color == "" {
+			layer.Color = validate.DefaultTemplateTextColor
+		}
+		if !validate.HexColor(layer.Color) {
+			return entity.TemplateLayer{}, errors.New("color must be in #RRGGBB or #RRGGBBAA format")
+		}
+
+		layer.Align = strings.ToLower(l.Align)
+		if layer.Align == "" {
+			layer.Align = validate.DefaultTemplateAlign
+		}
+		if !validate.AllowedAligns[layer.Align] {
+			return entity.TemplateLayer{}, errors.New("invalid align. Allowed: left, center, right")
+		}
+
+		layer.VAlign = strings.ToLower(l.VAlign)
+		if layer.VAlign == "" {
+			layer.VAlign = validate.DefaultTemplateVAlign
+		}
+		if !validate.AllowedVAligns[layer.VAlign] {
+			return entity.TemplateLayer{}, errors.New("invalid valign. Allowed: top, middle, bottom")
+		}
+
+		if l.MaxLines < 0 || l.MaxLines > validate.MaxTemplateLines {
+			return entity.TemplateLayer{}, fmt.Errorf("max_lines must be between 0 and %d", validate.MaxTemplateLines)
+		}
+		layer.MaxLines = l.MaxLines
+	}
+
+	return layer, nil
+}
+
+func templateResponse(t *entity.Template) response.Template {
+	return response.Template{
+		ID:         t.ID.String(),
+		Name:       t.Name,
+		Width:      t.Width,
+		Height:     t.Height,
+		Background: t.Background,
+		Layers:     t.Layers,
+		CreatedAt:  t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:  t.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary 	Get tile pyramid file
+// @Description Serves files of the pyramid built by tiles operation: descriptor(image.dzi or tiles.json, also returned for empty path), DZI tiles image_files/{level}/{col}_{row}.{format} or XYZ tiles {z}/{x}/{y}.{format}
+// @Tags 		tiles
+// @Produce 	application/xml,application/json,image/jpeg,image/png
+// @Param 		id 	 path string true "Image ID(uuid)"
+// @Param 		path path string true "File path inside the pyramid"
+// @Success 	200 {file} 	binary
+// @Failure 	400 {object} response.Error "Invalid ID or path"
+// @Failure 	404 {object} response.Error "Image has no tiles or tile not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/image/{id}/tiles/{path} [get]
+func (r *V1) getTile(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	name := ctx.Params("*")
+	if name != "" && !validate.TilePath.MatchString(name) {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid tile path")
+	}
+
+	body, name, err := r.img.DownloadTile(ctx.UserContext(), id, name)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "tile not found")
+		}
+		r.logger.Error(err, "restapi - v1 - getTile")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	ctx.Set(fiber.HeaderContentType, validate.TileContentTypes[path.Ext(name)])
+
+	return ctx.SendStream(body)
+}
@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+)
+
+// sniffContentType читает начало файла, определяет формат и возвращает позицию чтения в начало.
+func sniffContentType(r io.ReadSeeker) (string, error) {
+	head := make([]byte, validate.SniffLen)
+
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("sniffContentType - io.ReadFull: %w", err)
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("sniffContentType - r.Seek: %w", err)
+	}
+
+	return validate.SniffContentType(head[:n]), nil
+}
+
+// uploadError - отказ в приеме файла: код и текст для клиента, внутренняя причина - для лога.
+type uploadError struct {
+	code int
+	msg  string
+	err  error
+}
+
+// openUpload проверяет размер, формат по содержимому и расширение загруженного файла и открывает его.
+// SVG дополнительно проверяется на скрипты и внешние ссылки. Закрыть файл должен вызывающий.
+func openUpload(file *multipart.FileHeader) (multipart.File, string, *uploadError) {
+	// 1. валидация размера
+	if file.Size == 0 {
+		return nil, "", &uploadError{code: http.StatusBadRequest, msg: "file is empty"}
+	}
+
+	if file.Size > validate.MaxFileSize {
+		return nil, "", &uploadError{
+			code: http.StatusRequestEntityTooLarge,
+			msg:  fmt.Sprintf("file size cant be more than %d bytes", validate.MaxFileSize),
+		}
+	}
+
+	// 2. открытие файла
+	fileReader, err := file.Open()
+	if err != nil {
+		return nil, "", &uploadError{code: http.StatusInternalServerError, msg: "problems with opening the file", err: err}
+	}
+
+	contentType, uerr := checkUpload(file.Filename, file.Header.Get("Content-Type"), fileReader)
+	if uerr != nil {
+		fileReader.Close()
+		return nil, "", uerr
+	}
+
+	return fileReader, contentType, nil
+}
+
+// checkUpload проверяет формат по содержимому и расширение файла с именем name и заявленным клиентом
+// типом declaredType. Читатель возвращается в начало.
+func checkUpload(name, declaredType string, fileReader io.ReadSeeker) (string, *uploadError) {
+	// 3. определяем реальный формат по содержимому, заголовку клиента не доверяем
+	contentType, err := sniffContentType(fileReader)
+	if err != nil {
+		return "", &uploadError{code: http.StatusInternalServerError, msg: "problems with reading the file", err: err}
+	}
+	if !validate.AllowedContentTypes[contentType] {
+		return "", &uploadError{
+			code: http.StatusUnsupportedMediaType,
+			msg:  "unsupported file type. Allowed: jpeg, png, gif, bmp, tiff, svg",
+		}
+	}
+	if !validate.DeclaredTypeMatches(declaredType, contentType) {
+		return "", &uploadError{code: http.StatusUnsupportedMediaType, msg: "file content doesn't match its content type"}
+	}
+
+	// 4. валидация расширения
+	ext := strings.ToLower(filepath.Ext(name))
+	extContentType, ok := validate.AllowedExtensions[ext]
+	if !ok {
+		return "", &uploadError{
+			code: http.StatusUnsupportedMediaType,
+			msg:  "unsupported file extension. Allowed: .jpg, .jpeg, .png, .gif, .bmp, .tif, .tiff, .svg",
+		}
+	}
+	if extContentType != contentType {
+		return "", &uploadError{code: http.StatusUnsupportedMediaType, msg: "file extension doesn't match its content"}
+	}
+
+	// 4.1 SVG проверяем целиком: без скриптов и внешних ссылок
+	if contentType == validate.SVGContentType {
+		data, err := io.ReadAll(fileReader)
+		if err != nil {
+			return "", &uploadError{code: http.StatusInternalServerError, msg: "problems with reading the file", err: err}
+		}
+		if err := validate.CheckSVG(data); err != nil {
+			return "", &uploadError{code: http.StatusBadRequest, msg: err.Error()}
+		}
+		if _, err := fileReader.Seek(0, io.SeekStart); err != nil {
+			return "", &uploadError{code: http.StatusInternalServerError, msg: "problems with reading the file", err: err}
+		}
+	}
+
+	return contentType, nil
+}
+
+// checkTransformOutput отклоняет transform, результат которого для этого исходника превысит
+// предел площади: без явного размера холст - габариты преобразованного изображения.
+// Исходник читается только до заголовка; размер растра SVG проверит обработчик.
+func (r *V1) checkTransformOutput(ctx context.Context, op dto.Operation, src io.ReadSeeker, contentType string) *uploadError {
+	if op.Transform == nil || contentType == validate.SVGContentType {
+		return nil
+	}
+
+	cfg, _, err := image.DecodeConfig(src)
+	if err != nil {
+		return &uploadError{code: http.StatusUnprocessableEntity, msg: "image can't be decoded"}
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return &uploadError{code: http.StatusInternalServerError, msg: "problems with reading the file", err: err}
+	}
+
+	width, height, err := r.prc.TransformSize(ctx, cfg.Width, cfg.Height, *op.Transform)
+	if err != nil || width*height > validate.MaxTransformPixels {
+		return &uploadError{
+			code: http.StatusRequestEntityTooLarge,
+			msg:  fmt.Sprintf("transform is degenerate or its output has more than %d pixels", validate.MaxTransformPixels),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary  	Upload image by URL
+// @Description Downloads image from the URL and processes it like /upload. Only public http(s) addresses are fetched: loopback, private, link-local and other internal networks are rejected on every connection, including redirects
+// @Tags 		images
+// @Accept 		mpfd
+// @Produce 	json
+// @Param 		url 	  formData string true  "Image URL(http or https)"
+// @Param 		operation formData string true  "Operation(same values and fields as /upload)"
+// @Success 	201 {object} response.ProcessImage
+// @Failure 	400 {object} response.Error "Invalid or forbidden URL, empty file or wrong parameters"
+// @Failure 	413 {object} response.Error "File or transform output too large"
+// @Failure 	415 {object} response.Error "Unsupported format"
+// @Failure 	502 {object} response.Error "Remote server error"
+// @Failure 	504 {object} response.Error "Remote server timeout"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/upload/url [post]
+func (r *V1) uploadFromURL(ctx *fiber.Ctx) error {
+	rawURL := strings.TrimSpace(ctx.FormValue("url"))
+	if rawURL == "" {
+		return errorResponse(ctx, http.StatusBadRequest, "url is required")
+	}
+	if len(rawURL) > validate.MaxURLLen {
+		return errorResponse(ctx, http.StatusBadRequest,
+			fmt.Sprintf("url cant be longer than %d characters", validate.MaxURLLen))
+	}
+
+	// 1. валидация операции до скачивания, чтобы не ходить в сеть ради заведомо плохого запроса
+	op, err := parseOperation(ctx)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	// 2. скачиваем
+	file, err := r.img.FetchURL(ctx.UserContext(), rawURL)
+	if err != nil {
+		var netErr net.Error
+
+		switch {
+		case errors.Is(err, errs.ErrURLNotAllowed):
+			return errorResponse(ctx, http.StatusBadRequest, "url is not allowed")
+		case errors.Is(err, errs.ErrRemoteTooLarge):
+			return errorResponse(ctx, http.StatusRequestEntityTooLarge, "remote file is too large")
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return errorResponse(ctx, http.StatusGatewayTimeout, "remote server timed out")
+		}
+
+		r.logger.Error(err, "restapi - v1 - uploadFromURL")
+
+		return errorResponse(ctx, http.StatusBadGateway, "failed to fetch url")
+	}
+
+	// 3. валидация размера
+	size := int64(len(file.Data))
+	if size == 0 {
+		return errorResponse(ctx, http.StatusBadRequest, "file is empty")
+	}
+	if size > validate.MaxFileSize {
+		return errorResponse(ctx, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("file size cant be more than %d bytes", validate.MaxFileSize))
+	}
+
+	// 4. определяем формат по содержимому, заголовку удаленного сервера не доверяем
+	contentType := validate.SniffContentType(file.Data[:min(len(file.Data), validate.SniffLen)])
+	if !validate.AllowedContentTypes[contentType] {
+		return errorResponse(ctx, http.StatusUnsupportedMediaType,
+			"unsupported file type. Allowed: jpeg, png, gif, bmp, tiff, svg")
+	}
+	// тип, который отдал удаленный сервер, - его настройка, а не заявление клиента: CDN часто ошибаются,
+	// поэтому расхождение только логируем и верим содержимому
+	if !validate.DeclaredTypeMatches(file.ContentType, contentType) {
+		r.logger.Warn("restapi - v1 - uploadFromURL - remote content type %q doesn't match content %s", file.ContentType, contentType)
+	}
+
+	// 4.1 SVG проверяем целиком: без скриптов и внешних ссылок
+	if contentType == validate.SVGContentType {
+		if err := validate.CheckSVG(file.Data); err != nil {
+			return errorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
+
+		opts, err := parseRasterizeOptions(ctx)
+		if err != nil {
+			return errorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
+		op.Rasterize = &opts
+	}
+
+	if uerr := r.checkTransformOutput(ctx.UserContext(), op, bytes.NewReader(file.Data), contentType); uerr != nil {
+		return errorResponse(ctx, uerr.code, uerr.msg)
+	}
+
+	// 5. загружаем
+	name := remoteFileName(file.Name, contentType)
+	image, err := r.img.UploadNewImage(ctx.UserContext(), bytes.NewReader(file.Data), name, contentType, size, op)
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - uploadFromURL")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	// 6. ответ
+	resp := response.ProcessImage{
+		ImageID:      image.ID.String(),
+		OriginalName: image.OriginalName,
+		Size:         int(image.Size),
+		ContentType:  image.ContentType,
+		Status:       string(image.Status),
+		Operation:    op.Operation,
+		CreatedAt:    image.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(resp)
+}
+
+// remoteFileName - имя скачанного файла: последний сегмент пути URL с расширением,
+// соответствующим содержимому. Ссылки вида /image?id=1 получают имя "image".
+func remoteFileName(name, contentType string) string {
+	if name == "" {
+		name = "image"
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	if validate.AllowedExtensions[ext] == contentType {
+		return name
+	}
+
+	return name + validate.CanonicalExtensions[contentType]
+}
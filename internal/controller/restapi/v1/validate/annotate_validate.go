@@ -0,0 +1,27 @@
+package validate
+
+const (
+	MaxAnnotateShapes int = 100
+
+	MaxStrokeWidth     float64 = 50
+	DefaultStrokeWidth float64 = 3
+
+	MinFontSize     float64 = 8
+	MaxFontSize     float64 = 200
+	DefaultFontSize float64 = 16
+
+	MaxLabelLen int = 200
+
+	// координаты могут выходить за изображение, но не бесконечно
+	MaxShapeCoordinate float64 = 100000
+
+	DefaultAnnotateColor = "#ff0000"
+)
+
+var AllowedShapeTypes = map[string]bool{
+	"rect":    true,
+	"ellipse": true,
+	"line":    true,
+	"arrow":   true,
+	"text":    true,
+}
@@ -0,0 +1,8 @@
+package validate
+
+const (
+	DefaultKeyTolerance int = 30
+
+	MaxKeyFeather     int = 20
+	DefaultKeyFeather int = 2
+)
@@ -0,0 +1,15 @@
+package validate
+
+const (
+	MaxBatchFiles  int = 100
+	MaxBatchDelete int = 1000
+
+	// поля формы пакета: не больше MaxBatchFields штук и MaxBatchFieldSize байт каждое
+	// (operations на MaxBatchFiles файлов укладывается с запасом)
+	MaxBatchFields    int   = 100
+	MaxBatchFieldSize int64 = 1 << 20
+
+	// сколько файлов пакета загружаем/удаляем одновременно; столько файлов пакета
+	// (плюс читаемый) одновременно держится в памяти
+	BatchConcurrency int = 8
+)
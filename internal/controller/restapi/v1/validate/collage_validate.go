@@ -0,0 +1,38 @@
+package validate
+
+import "regexp"
+
+const (
+	MaxCollageCells int = 100
+
+	MinCollageCellSize int = 16
+	MaxCollageCellSize int = 2000
+
+	MaxCollageGap int = 200
+
+	DefaultCollageBackground = "#ffffff"
+	DefaultCollageFit        = "fill"
+	DefaultCollageFormat     = "jpeg"
+)
+
+var (
+	hexColorRe = regexp.MustCompile(`^#([0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+	AllowedCollageFits = map[string]bool{
+		"fill":    true,
+		"fit":     true,
+		"stretch": true,
+	}
+
+	// формат результата -> content type
+	OutputFormats = map[string]string{
+		"jpeg": "image/jpeg",
+		"jpg":  "image/jpeg",
+		"png":  "image/png",
+	}
+)
+
+// HexColor проверяет цвет в формате #RRGGBB или #RRGGBBAA.
+func HexColor(s string) bool {
+	return hexColorRe.MatchString(s)
+}
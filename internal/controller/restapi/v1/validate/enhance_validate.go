@@ -0,0 +1,3 @@
+package validate
+
+const DefaultEnhanceStrength float64 = 0.5
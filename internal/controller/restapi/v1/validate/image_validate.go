@@ -11,19 +11,30 @@ const (
 
 	MinTextLen int = 10
 	MaxTextLen int = 64
+
+	// невидимый водяной знак несет UUID или короткую строку
+	MaxMarkPayloadLen int = 16
 )
 
 var (
 	AllowedContentTypes = map[string]bool{
-		"image/jpeg": true,
-		"image/jpg":  true,
-		"image/png":  true,
+		"image/jpeg":    true,
+		"image/png":     true,
+		"image/gif":     true,
+		"image/bmp":     true,
+		"image/tiff":    true,
+		"image/svg+xml": true,
 	}
 
-	AllowedExtensions = map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".webp": true,
+	// расширение -> тип содержимого, которому оно должно соответствовать
+	AllowedExtensions = map[string]string{
+		".jpg":  "image/jpeg",
+		".jpeg": "image/jpeg",
+		".png":  "image/png",
+		".gif":  "image/gif",
+		".bmp":  "image/bmp",
+		".tif":  "image/tiff",
+		".tiff": "image/tiff",
+		".svg":  "image/svg+xml",
 	}
 )
@@ -0,0 +1,29 @@
+package validate
+
+const (
+	DefaultListLimit int = 50
+	MaxListLimit     int = 200
+
+	MaxNamePrefixLen int = 255
+)
+
+var (
+	AllowedListStatuses = map[string]bool{
+		"pending":   true,
+		"processed": true,
+		"failed":    true,
+	}
+
+	AllowedQualityGrades = map[string]bool{
+		"good": true,
+		"fair": true,
+		"poor": true,
+	}
+
+	AllowedQualityIssues = map[string]bool{
+		"blurry":         true,
+		"overexposed":    true,
+		"underexposed":   true,
+		"low_resolution": true,
+	}
+)
@@ -0,0 +1,18 @@
+package validate
+
+import "regexp"
+
+const (
+	MaxMetadataFieldLen int = 512
+
+	MaxCustomMetadata      int = 20
+	MaxCustomMetadataValue int = 256
+)
+
+// ключ произвольного поля становится именем элемента XMP
+var metadataKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]{0,63}$`)
+
+// MetadataKey проверяет ключ произвольного поля метаданных.
+func MetadataKey(s string) bool {
+	return metadataKeyRe.MatchString(s)
+}
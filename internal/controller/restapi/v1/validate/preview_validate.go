@@ -0,0 +1,49 @@
+package validate
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+
+	// декодеры для чтения размеров без декодирования всего изображения
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+)
+
+const (
+	// PreviewMarkPayload - невидимый знак превью несет этот текст, если payload не задан.
+	PreviewMarkPayload = "preview"
+
+	// сравнение и поиск знака декодируют изображение прямо в запросе: 100 МБ NRGBA на изображение
+	MaxInlinePixels int = 25_000_000
+)
+
+var (
+	// операции, результат которых - один файл; тайлы и favicon в превью не входят
+	AllowedPreviewOperations = map[string]bool{
+		"resize":              true,
+		"thumbnail":           true,
+		"watermark":           true,
+		"quantize":            true,
+		"auto_enhance":        true,
+		"trim":                true,
+		"invisible_watermark": true,
+		"remove_background":   true,
+		"transform":           true,
+		"annotate":            true,
+	}
+)
+
+// ImageSize читает размеры растрового изображения из заголовка.
+func ImageSize(data []byte) (int, int, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("ImageSize - image.DecodeConfig: %w", err)
+	}
+
+	return cfg.Width, cfg.Height, nil
+}
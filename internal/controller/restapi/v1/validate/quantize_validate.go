@@ -0,0 +1,22 @@
+package validate
+
+const (
+	MinQuantizeColors     int = 2
+	MaxQuantizeColors     int = 256
+	DefaultQuantizeColors int = 64
+
+	DefaultQuantizeAlgorithm = "median_cut"
+	DefaultQuantizeFormat    = "png"
+)
+
+var (
+	AllowedQuantizeAlgorithms = map[string]bool{
+		"median_cut": true,
+		"kmeans":     true,
+	}
+
+	AllowedQuantizeFormats = map[string]bool{
+		"png": true,
+		"gif": true,
+	}
+)
@@ -0,0 +1,77 @@
+package validate
+
+import (
+	"bytes"
+	"strings"
+)
+
+// SniffLen - сколько первых байт файла нужно для определения формата.
+const SniffLen = 512
+
+var contentTypeAliases = map[string]string{
+	"image/jpg":      "image/jpeg",
+	"image/pjpeg":    "image/jpeg",
+	"image/x-ms-bmp": "image/bmp",
+	"image/x-bmp":    "image/bmp",
+	"image/tif":      "image/tiff",
+	"image/x-tiff":   "image/tiff",
+}
+
+var signatures = []struct {
+	prefix      []byte
+	contentType string
+}{
+	{[]byte("\xFF\xD8\xFF"), "image/jpeg"},
+	{[]byte("\x89PNG\r\n\x1A\n"), "image/png"},
+	{[]byte("GIF87a"), "image/gif"},
+	{[]byte("GIF89a"), "image/gif"},
+	{[]byte("BM"), "image/bmp"},
+	{[]byte("II*\x00"), "image/tiff"},
+	{[]byte("MM\x00*"), "image/tiff"},
+}
+
+// SniffContentType определяет формат изображения по сигнатуре в начале файла.
+// Возвращает пустую строку, если формат не распознан.
+func SniffContentType(head []byte) string {
+	for _, sig := range signatures {
+		if bytes.HasPrefix(head, sig.prefix) {
+			return sig.contentType
+		}
+	}
+
+	// RIFF....WEBP
+	if len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WEBP")) {
+		return "image/webp"
+	}
+
+	if isSVGDocument(head) {
+		return SVGContentType
+	}
+
+	return ""
+}
+
+// NormalizeContentType приводит заявленный клиентом тип к каноничному виду.
+func NormalizeContentType(contentType string) string {
+	ct := strings.ToLower(strings.TrimSpace(contentType))
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = strings.TrimSpace(ct[:i])
+	}
+
+	if alias, ok := contentTypeAliases[ct]; ok {
+		return alias
+	}
+
+	return ct
+}
+
+// DeclaredTypeMatches проверяет, что заявленный тип не противоречит реальному.
+// Клиенты, не знающие тип (пустой или application/octet-stream), не отклоняются.
+func DeclaredTypeMatches(declared, sniffed string) bool {
+	declared = NormalizeContentType(declared)
+	if declared == "" || declared == "application/octet-stream" {
+		return true
+	}
+
+	return declared == sniffed
+}
@@ -0,0 +1,26 @@
+package validate
+
+import "regexp"
+
+const (
+	MaxSpriteImages int = 200
+
+	MaxSpritePadding     int = 64
+	DefaultSpritePadding int = 2
+
+	MinSpriteIconSize int = 8
+	MaxSpriteIconSize int = 1024
+)
+
+var (
+	// имя дополнительного файла результата
+	FileName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+	// расширение дополнительного файла -> тип содержимого
+	FileContentTypes = map[string]string{
+		".json":        "application/json",
+		".webmanifest": "application/manifest+json",
+		".png":         "image/png",
+		".ico":         "image/x-icon",
+	}
+)
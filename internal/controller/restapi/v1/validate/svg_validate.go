@@ -0,0 +1,151 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	SVGContentType = "image/svg+xml"
+
+	MinSVGDPI float64 = 10
+	MaxSVGDPI float64 = 1200
+)
+
+var (
+	ErrInvalidSVG = errors.New("invalid svg document")
+
+	// элементы, позволяющие исполнять код или встраивать внешнее содержимое
+	forbiddenSVGElements = map[string]bool{
+		"script":        true,
+		"foreignobject": true,
+		"iframe":        true,
+		"embed":         true,
+		"object":        true,
+		"audio":         true,
+		"video":         true,
+		"handler":       true,
+		"listener":      true,
+	}
+)
+
+// isSVGDocument определяет SVG по началу файла: xml-пролог, комментарий или сразу <svg.
+func isSVGDocument(head []byte) bool {
+	head = bytes.TrimLeft(head, "\xEF\xBB\xBF \t\r\n")
+
+	return (bytes.HasPrefix(head, []byte("<?xml")) || bytes.HasPrefix(head, []byte("<svg")) ||
+		bytes.HasPrefix(head, []byte("<!--"))) && bytes.Contains(head, []byte("<svg"))
+}
+
+// CheckSVG проверяет, что SVG безопасно хранить и отдавать: без скриптов,
+// обработчиков событий, DTD/сущностей и ссылок на внешние ресурсы.
+func CheckSVG(data []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+
+	rootSeen := false
+	inStyle := false
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ErrInvalidSVG
+		}
+
+		switch t := tok.(type) {
+		case xml.Directive:
+			return errors.New("svg must not contain DOCTYPE or entity declarations")
+		case xml.ProcInst:
+			if t.Target != "xml" {
+				return errors.New("svg must not contain processing instructions")
+			}
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+
+			if !rootSeen {
+				if name != "svg" {
+					return ErrInvalidSVG
+				}
+				rootSeen = true
+			}
+
+			if forbiddenSVGElements[name] {
+				return errors.New("svg must not contain <" + t.Name.Local + "> elements")
+			}
+
+			for _, attr := range t.Attr {
+				if err := checkSVGAttr(attr); err != nil {
+					return err
+				}
+			}
+
+			inStyle = name == "style"
+		case xml.EndElement:
+			inStyle = false
+		case xml.CharData:
+			if inStyle && !safeCSS(string(t)) {
+				return errors.New("svg styles must not reference external resources")
+			}
+		}
+	}
+
+	if !rootSeen {
+		return ErrInvalidSVG
+	}
+
+	return nil
+}
+
+func checkSVGAttr(attr xml.Attr) error {
+	name := strings.ToLower(attr.Name.Local)
+	value := strings.ToLower(strings.TrimSpace(attr.Value))
+
+	if strings.HasPrefix(name, "on") {
+		return errors.New("svg must not contain event handlers")
+	}
+
+	if strings.Contains(value, "javascript:") {
+		return errors.New("svg must not contain scripts")
+	}
+
+	if name == "href" && !localReference(value) {
+		return errors.New("svg must not reference external resources")
+	}
+
+	if !safeCSS(value) {
+		return errors.New("svg must not reference external resources")
+	}
+
+	return nil
+}
+
+// localReference - ссылка на элемент внутри документа или встроенные данные изображения.
+func localReference(v string) bool {
+	return strings.HasPrefix(v, "#") || strings.HasPrefix(v, "data:image/")
+}
+
+// safeCSS проверяет, что все url(...) ведут внутрь документа и нет @import.
+func safeCSS(css string) bool {
+	css = strings.ToLower(css)
+	if strings.Contains(css, "@import") {
+		return false
+	}
+
+	for {
+		i := strings.Index(css, "url(")
+		if i < 0 {
+			return true
+		}
+		css = css[i+len("url("):]
+
+		ref := strings.Trim(strings.TrimSpace(css), `'"`)
+		if !localReference(ref) {
+			return false
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package validate
+
+import "testing"
+
+func TestCheckSVG(t *testing.T) {
+	tests := []struct {
+		name    string
+		svg     string
+		wantErr bool
+	}{
+		{
+			name: "plain shapes",
+			svg:  `<svg xmlns="http://www.w3.org/2000/svg" width="10" height="10"><rect width="10" height="10" fill="red"/></svg>`,
+		},
+		{
+			name: "xml prolog",
+			svg:  `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><circle r="5"/></svg>`,
+		},
+		{
+			name: "local href",
+			svg:  `<svg xmlns="http://www.w3.org/2000/svg"><defs><g id="a"/></defs><use href="#a"/></svg>`,
+		},
+		{
+			name: "embedded raster data uri",
+			svg:  `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><image xlink:href="data:image/png;base64,iVBORw0KGgo="/></svg>`,
+		},
+		{
+			name: "local url in style",
+			svg:  `<svg xmlns="http://www.w3.org/2000/svg"><rect style="fill: url(#grad)"/></svg>`,
+		},
+		{
+			name:    "script element",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "script element in other case",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><SCRIPT>alert(1)</SCRIPT></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "foreignObject",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><foreignObject><div/></foreignObject></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "onload handler",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"/>`,
+			wantErr: true,
+		},
+		{
+			name:    "onclick handler on child",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><rect onClick="alert(1)"/></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "javascript uri",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><a href="javascript:alert(1)"><rect/></a></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "external href",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><image href="http://169.254.169.254/latest"/></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "external xlink:href",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><image xlink:href="https://example.com/a.png"/></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "file href",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><image href="file:///etc/passwd"/></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "non-image data uri",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><a href="data:text/html;base64,PHNjcmlwdD4="><rect/></a></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "external url in style attribute",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><rect style="fill: url('https://example.com/x')"/></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "import in style element",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><style>@import "https://example.com/a.css";</style></svg>`,
+			wantErr: true,
+		},
+		{
+			name: "entity expansion",
+			svg: `<?xml version="1.0"?><!DOCTYPE svg [<!ENTITY a "aaaaaaaaaa"><!ENTITY b "&a;&a;&a;&a;&a;&a;&a;&a;">]>` +
+				`<svg xmlns="http://www.w3.org/2000/svg"><text>&b;</text></svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "external entity",
+			svg:     `<?xml version="1.0"?><!DOCTYPE svg [<!ENTITY x SYSTEM "file:///etc/passwd">]><svg xmlns="http://www.w3.org/2000/svg">&x;</svg>`,
+			wantErr: true,
+		},
+		{
+			name:    "processing instruction",
+			svg:     `<?xml-stylesheet href="https://example.com/a.css"?><svg xmlns="http://www.w3.org/2000/svg"/>`,
+			wantErr: true,
+		},
+		{
+			name:    "root is not svg",
+			svg:     `<html><svg xmlns="http://www.w3.org/2000/svg"/></html>`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			svg:     `<svg xmlns="http://www.w3.org/2000/svg"><rect>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSVG([]byte(tt.svg))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckSVG() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package validate
+
+import "regexp"
+
+const (
+	MaxTemplateNameLen int = 100
+
+	MinTemplateSide int = 16
+	MaxTemplateSide int = 4000
+
+	MaxTemplateLayers int = 20
+	MaxTemplateText   int = 500
+	MaxTemplateLines  int = 20
+
+	DefaultTemplateFontSize float64 = 48
+
+	MinLineHeight     float64 = 0.8
+	MaxLineHeight     float64 = 3
+	DefaultLineHeight float64 = 1.2
+
+	DefaultTemplateBackground = "#ffffff"
+	DefaultTemplateTextColor  = "#000000"
+	DefaultTemplateFont       = "regular"
+	DefaultTemplateAlign      = "left"
+	DefaultTemplateVAlign     = "top"
+	DefaultTemplateFormat     = "png"
+)
+
+var (
+	// имя слота или текстового поля в запросе рендера
+	LayerName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+	AllowedLayerTypes = map[string]bool{
+		"base": true,
+		"slot": true,
+		"text": true,
+	}
+
+	AllowedFonts = map[string]bool{
+		"regular": true,
+		"bold":    true,
+		"italic":  true,
+		"mono":    true,
+	}
+
+	AllowedAligns = map[string]bool{
+		"left":   true,
+		"center": true,
+		"right":  true,
+	}
+
+	AllowedVAligns = map[string]bool{
+		"top":    true,
+		"middle": true,
+		"bottom": true,
+	}
+)
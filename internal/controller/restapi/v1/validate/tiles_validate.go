@@ -0,0 +1,38 @@
+package validate
+
+import "regexp"
+
+const (
+	DefaultTileLayout = "dzi"
+	DefaultTileFormat = "jpeg"
+
+	MinTileSize     int = 64
+	MaxTileSize     int = 1024
+	DefaultTileSize int = 256
+
+	MaxTileOverlap     int = 8
+	DefaultTileOverlap int = 1
+)
+
+var (
+	AllowedTileLayouts = map[string]bool{
+		"dzi": true,
+		"xyz": true,
+	}
+
+	AllowedTileFormats = map[string]bool{
+		"jpeg": true,
+		"png":  true,
+	}
+
+	// допустимые пути внутри пирамиды: дескрипторы, тайлы dzi и xyz
+	TilePath = regexp.MustCompile(`^(image\.dzi|tiles\.json|image_files/\d{1,2}/\d{1,5}_\d{1,5}\.(jpeg|png)|\d{1,2}/\d{1,5}/\d{1,5}\.(jpeg|png))$`)
+
+	// расширение файла пирамиды -> тип содержимого
+	TileContentTypes = map[string]string{
+		".dzi":  "application/xml",
+		".json": "application/json",
+		".jpeg": "image/jpeg",
+		".png":  "image/png",
+	}
+)
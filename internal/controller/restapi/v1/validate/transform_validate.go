@@ -0,0 +1,16 @@
+package validate
+
+const (
+	TransformMatrixLen  int = 6
+	TransformCornersLen int = 8
+
+	DefaultInterpolation = "bilinear"
+
+	// площадь результата; совпадает с пределом холста обработчика
+	MaxTransformPixels int = 16_000_000
+)
+
+var AllowedInterpolations = map[string]bool{
+	"bilinear": true,
+	"bicubic":  true,
+}
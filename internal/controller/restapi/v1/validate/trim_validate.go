@@ -0,0 +1,8 @@
+package validate
+
+const (
+	DefaultTrimTolerance int = 10
+	MaxTrimTolerance     int = 255
+
+	MaxTrimPadding int = 1000
+)
@@ -0,0 +1,13 @@
+package validate
+
+const MaxURLLen int = 2048
+
+// тип содержимого -> расширение, которое дописывается к имени скачанного файла
+var CanonicalExtensions = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"image/bmp":     ".bmp",
+	"image/tiff":    ".tiff",
+	"image/svg+xml": ".svg",
+}
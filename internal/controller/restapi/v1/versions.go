@@ -0,0 +1,156 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary 	List image versions
+// @Description Lists processing results of the image, newest first. Every processing stores a new immutable version
+// @Tags 		versions
+// @Produce 	json
+// @Param 		id path string true "Image ID(uuid)"
+// @Success 	200 {object} response.ImageVersions
+// @Failure 	400 {object} response.Error "Invalid ID"
+// @Failure 	404 {object} response.Error "Image not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/image/{id}/versions [get]
+func (r *V1) listVersions(ctx *fiber.Ctx) error {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid id")
+	}
+
+	image, err := r.img.GetImage(ctx.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "image not found")
+		}
+		r.logger.Error(err, "restapi - v1 - listVersions")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	versions, err := r.img.ListVersions(ctx.UserContext(), id)
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - listVersions")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.ImageVersions{
+		ImageID:  id.String(),
+		Versions: make([]response.ImageVersion, 0, len(versions)),
+	}
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, versionResponse(v, image.CurrentVersionID))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
+
+// @Summary 	Get image version
+// @Description Downloads the processed image of the given version
+// @Tags 		versions
+// @Produce 	image/jpeg,image/png,image/gif,image/bmp,image/tiff,image/x-icon
+// @Param 		id 		path string true "Image ID(uuid)"
+// @Param 		version path int 	true "Version number"
+// @Success 	200 {file} 	binary
+// @Failure 	400 {object} response.Error "Invalid ID or version"
+// @Failure 	404 {object} response.Error "Version not found"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/image/{id}/versions/{version} [get]
+func (r *V1) getVersion(ctx *fiber.Ctx) error {
+	id, number, err := parseVersionParams(ctx)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	version, err := r.img.GetVersion(ctx.UserContext(), id, number)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "version not found")
+		}
+		r.logger.Error(err, "restapi - v1 - getVersion")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	body, err := r.img.DownloadImage(ctx.UserContext(), version.Key)
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - getVersion")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	ctx.Set(fiber.HeaderContentType, version.ContentType)
+
+	return ctx.SendStream(body)
+}
+
+// @Summary 	Set current version
+// @Description Makes the given version the processed image returned by GET /v1/image/{id}
+// @Tags 		versions
+// @Produce 	json
+// @Param 		id 		path string true "Image ID(uuid)"
+// @Param 		version path int 	true "Version number"
+// @Success 	200 {object} response.ImageVersion
+// @Failure 	400 {object} response.Error "Invalid ID or version"
+// @Failure 	404 {object} response.Error "Version not found"
+// @Failure 	409 {object} response.Error "Image is being processed"
+// @Failure 	500 {object} response.Error "Internal"
+// @Router 		/v1/image/{id}/versions/{version}/current [post]
+func (r *V1) setCurrentVersion(ctx *fiber.Ctx) error {
+	id, number, err := parseVersionParams(ctx)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	version, err := r.img.SetCurrentVersion(ctx.UserContext(), id, number)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrRecordNotFound):
+			return errorResponse(ctx, http.StatusNotFound, "version not found")
+		case errors.Is(err, errs.ErrImageBusy):
+			return errorResponse(ctx, http.StatusConflict, "image is being processed, try again later")
+		}
+		r.logger.Error(err, "restapi - v1 - setCurrentVersion")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	return ctx.Status(http.StatusOK).JSON(versionResponse(version, &version.ID))
+}
+
+func parseVersionParams(ctx *fiber.Ctx) (uuid.UUID, int, error) {
+	id, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return uuid.Nil, 0, errors.New("invalid id")
+	}
+
+	number, err := strconv.Atoi(ctx.Params("version"))
+	if err != nil || number < 1 {
+		return uuid.Nil, 0, errors.New("invalid version")
+	}
+
+	return id, number, nil
+}
+
+func versionResponse(v *entity.ImageVersion, currentID *uuid.UUID) response.ImageVersion {
+	return response.ImageVersion{
+		Version:     v.Number,
+		ContentType: v.ContentType,
+		Size:        int(v.Size),
+		Operation:   v.Operation,
+		Spec:        v.Spec,
+		Current:     currentID != nil && *currentID == v.ID,
+		CreatedAt:   v.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
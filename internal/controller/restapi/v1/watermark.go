@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/Image-Processor/internal/controller/restapi/v1/validate"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary  	Detect invisible watermark
+// @Description Extracts the payload embedded by invisible_watermark operation. Survives moderate JPEG recompression and resizing, not cropping
+// @Tags 		watermark
+// @Accept 		mpfd
+// @Produce 	json
+// @Param 		file formData file true "Image file(jpg, png, gif, bmp, tiff)"
+// @Success 	200 {object} response.WatermarkDetection
+// @Failure 	400 {object} response.Error "Empty file"
+// @Failure 	413 {object} response.Error "File too large or has too many pixels"
+// @Failure 	415 {object} response.Error "Unsupported format"
+// @Failure 	422 {object} response.Error "Image can't be decoded"
+// @Failure 	429 {object} response.Error "Too many inline operations in progress"
+// @Failure 	500 {object} response.Error "Internal"
+// @Failure 	503 {object} response.Error "Detection timed out"
+// @Router 		/v1/watermark/detect [post]
+func (r *V1) detectWatermark(ctx *fiber.Ctx) error {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "file is required")
+	}
+
+	// 1. валидация размера
+	if file.Size == 0 {
+		return errorResponse(ctx, http.StatusBadRequest, "file is empty")
+	}
+
+	if file.Size > validate.MaxFileSize {
+		return errorResponse(ctx, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("file size cant be more than %d bytes", validate.MaxFileSize))
+	}
+
+	// 2. открытие файла
+	fileReader, err := file.Open()
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - detectWatermark")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "problems with opening the file")
+	}
+	defer fileReader.Close()
+
+	// 3. формат по содержимому; в векторе знака быть не может
+	contentType, err := sniffContentType(fileReader)
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - detectWatermark")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "problems with reading the file")
+	}
+	if !validate.AllowedContentTypes[contentType] || contentType == validate.SVGContentType {
+		return errorResponse(ctx, http.StatusUnsupportedMediaType, "unsupported file type. Allowed: jpeg, png, gif, bmp, tiff")
+	}
+
+	data, err := io.ReadAll(fileReader)
+	if err != nil {
+		r.logger.Error(err, "restapi - v1 - detectWatermark")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "problems with reading the file")
+	}
+
+	// 4. размер по заголовку до декодирования
+	if uerr := checkPixels(data, validate.MaxInlinePixels); uerr != nil {
+		return errorResponse(ctx, uerr.code, uerr.msg)
+	}
+
+	// 5. извлечение - в общем слоте и с таймаутом синхронной обработки
+	release, uerr := r.acquireSlot(ctx)
+	if uerr != nil {
+		return errorResponse(ctx, uerr.code, uerr.msg)
+	}
+	defer release()
+
+	cpuCtx, cpuCancel := context.WithTimeout(ctx.UserContext(), r.preview.Timeout)
+	defer cpuCancel()
+	res, err := r.prc.DetectWatermark(cpuCtx, data)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return errorResponse(ctx, http.StatusServiceUnavailable, "detection timed out")
+		}
+		r.logger.Error(err, "restapi - v1 - detectWatermark")
+
+		return errorResponse(ctx, http.StatusUnprocessableEntity, "image can't be decoded")
+	}
+
+	return ctx.Status(http.StatusOK).JSON(response.WatermarkDetection{
+		Found:      res.Found,
+		Payload:    res.Payload,
+		Confidence: math.Round(res.Confidence*100) / 100,
+	})
+}
@@ -0,0 +1,21 @@
+package dto
+
+// Shape - примитив разметки. Координаты в пикселях исходного изображения.
+type Shape struct {
+	Type        string  `json:"type"`                   // rect, ellipse, line, arrow, text
+	X           float64 `json:"x"`                      // левый верхний угол rect, ellipse, text; начало line, arrow
+	Y           float64 `json:"y"`                      //
+	Width       float64 `json:"width,omitempty"`        // rect, ellipse
+	Height      float64 `json:"height,omitempty"`       // rect, ellipse
+	X2          float64 `json:"x2,omitempty"`           // конец line, arrow
+	Y2          float64 `json:"y2,omitempty"`           //
+	Text        string  `json:"text,omitempty"`         // text
+	Color       string  `json:"color,omitempty"`        // цвет контура и текста
+	Fill        string  `json:"fill,omitempty"`         // заливка rect, ellipse и подложка text; пусто - без заливки
+	StrokeWidth float64 `json:"stroke_width,omitempty"` // толщина контура и линий
+	FontSize    float64 `json:"font_size,omitempty"`    // text
+}
+
+type AnnotateOptions struct {
+	Shapes []Shape `json:"shapes"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+type RemoveBackgroundOptions struct {
+	Color      string `json:"color,omitempty"` // #RRGGBB; пусто - определяется по краям изображения
+	Tolerance  int    `json:"tolerance"`       // допустимое отклонение канала от цвета фона, 0..255
+	Feather    int    `json:"feather"`         // ширина мягкого края в пикселях
+	Contiguous bool   `json:"contiguous"`      // убирать только фон, связанный с краями изображения
+}
@@ -0,0 +1,11 @@
+package dto
+
+type CollageLayout struct {
+	Rows       int    `json:"rows"`
+	Columns    int    `json:"columns"`
+	CellWidth  int    `json:"cell_width"`
+	CellHeight int    `json:"cell_height"`
+	Gap        int    `json:"gap"`
+	Background string `json:"background"`
+	Fit        string `json:"fit"` // fill, fit, stretch
+}
@@ -0,0 +1,9 @@
+package dto
+
+type Comparison struct {
+	SSIM   float64
+	PSNR   float64
+	Width  int
+	Height int
+	Diff   []byte // тепловая карта отличий в png, если запрошена
+}
@@ -0,0 +1,7 @@
+package dto
+
+type EnhanceOptions struct {
+	Equalize     bool    `json:"equalize"`
+	Strength     float64 `json:"strength"` // сила выравнивания гистограммы, 0..1
+	WhiteBalance bool    `json:"white_balance"`
+}
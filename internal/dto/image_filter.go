@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/google/uuid"
+)
+
+// ImageFilter - условия выборки изображений. Пустые поля не ограничивают выборку.
+type ImageFilter struct {
+	Status       entity.Status
+	ContentType  string
+	CreatedFrom  *time.Time // включительно
+	CreatedTo    *time.Time // не включительно
+	NamePrefix   string
+	MinSize      *int64
+	MaxSize      *int64
+	QualityGrade string       // good, fair, poor
+	QualityIssue string       // blurry, overexposed, underexposed, low_resolution
+	MinSharpness *float64     //
+	MaxSharpness *float64     //
+	After        *ImageCursor // страница начинается после этого изображения
+	Limit        int
+}
+
+// ImageCursor - позиция в выдаче, отсортированной по (created_at, id) по убыванию.
+type ImageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+type ImagePage struct {
+	Images []*entity.Image
+	Next   *ImageCursor // nil на последней странице
+}
@@ -0,0 +1,10 @@
+package dto
+
+// OutputMetadata - поля EXIF/XMP, которые записываются в результат обработки.
+type OutputMetadata struct {
+	Copyright   string            `json:"copyright,omitempty"`
+	Artist      string            `json:"artist,omitempty"`
+	Description string            `json:"description,omitempty"`
+	SourceURL   string            `json:"source_url,omitempty"`
+	Custom      map[string]string `json:"custom,omitempty"` // только в XMP
+}
@@ -5,4 +5,17 @@ type Operation struct {
 	Width     *int
 	Height    *int
 	Text      *string
+	Collage   *CollageLayout
+	Quantize  *QuantizeOptions
+	Trim      *TrimOptions
+	Mark      *InvisibleWatermarkOptions
+	Tiles     *TileOptions
+	Sprite    *SpriteOptions
+	Keying    *RemoveBackgroundOptions
+	Transform *TransformOptions
+	Annotate  *AnnotateOptions
+	Template  *TemplateRender
+	Output    *OutputMetadata   // EXIF/XMP поверх значений по умолчанию
+	Rasterize *RasterizeOptions // только для векторных исходников
+	Enhance   *EnhanceOptions   // сама операция auto_enhance или предобработка перед другой операцией
 }
@@ -0,0 +1,8 @@
+package dto
+
+type QuantizeOptions struct {
+	Colors    int    `json:"colors"`
+	Algorithm string `json:"algorithm"` // median_cut, kmeans
+	Dither    bool   `json:"dither"`
+	Format    string `json:"format"` // png, gif
+}
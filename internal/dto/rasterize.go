@@ -0,0 +1,9 @@
+package dto
+
+// RasterizeOptions - размер растеризации векторного исходника.
+// Нулевые значения - собственный размер изображения при 96 dpi.
+type RasterizeOptions struct {
+	Width  int     `json:"width,omitempty"`
+	Height int     `json:"height,omitempty"`
+	DPI    float64 `json:"dpi,omitempty"`
+}
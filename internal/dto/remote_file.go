@@ -0,0 +1,8 @@
+package dto
+
+// RemoteFile - файл, скачанный по URL.
+type RemoteFile struct {
+	Data        []byte
+	Name        string // последний сегмент пути итогового URL
+	ContentType string // заявленный сервером, как есть
+}
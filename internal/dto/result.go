@@ -0,0 +1,10 @@
+package dto
+
+import "github.com/andreyxaxa/Image-Processor/internal/entity"
+
+type Result struct {
+	Data        []byte
+	ContentType string
+	Metadata    entity.Metadata
+	Files       []File // объекты, которые хранятся рядом с результатом (тайлы)
+}
@@ -0,0 +1,21 @@
+package dto
+
+type SpriteOptions struct {
+	IDs      []string `json:"ids"`       // идентификаторы кадров в манифесте, по порядку исходников
+	Padding  int      `json:"padding"`   // отступ между кадрами
+	IconSize int      `json:"icon_size"` // 0 - исходный размер, иначе кадры вписываются в квадрат
+}
+
+type SpriteFrame struct {
+	ID     string `json:"id"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type SpriteManifest struct {
+	Width  int           `json:"width"`
+	Height int           `json:"height"`
+	Frames []SpriteFrame `json:"frames"`
+}
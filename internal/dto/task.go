@@ -2,8 +2,24 @@ package dto
 
 type Task struct {
 	Data      []byte
+	Sources   [][]byte
 	Operation string
 	Width     *int
 	Height    *int
 	Text      *string
+	Collage   *CollageLayout
+	Quantize  *QuantizeOptions
+	Trim      *TrimOptions
+	Mark      *InvisibleWatermarkOptions
+	Tiles     *TileOptions
+	Sprite    *SpriteOptions
+	Keying    *RemoveBackgroundOptions
+	Transform *TransformOptions
+	Annotate  *AnnotateOptions
+	Template  *TemplateRender
+	Output    *OutputMetadata   // EXIF/XMP поверх значений по умолчанию
+	Rasterize *RasterizeOptions // только для векторных исходников
+	Enhance   *EnhanceOptions
+	// оценить качество оригинала - только там, где метаданные сохраняются
+	AssessQuality bool
 }
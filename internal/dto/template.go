@@ -0,0 +1,31 @@
+package dto
+
+// TemplateRender - шаблон, готовый к отрисовке воркером: изображения слоев
+// приходят в Task.Sources, тексты уже подставлены.
+type TemplateRender struct {
+	Width      int                 `json:"width"`
+	Height     int                 `json:"height"`
+	Background string              `json:"background"`
+	Layers     []TemplateLayerDraw `json:"layers"`
+}
+
+type TemplateLayerDraw struct {
+	Type   string `json:"type"`             // image, text
+	Source int    `json:"source,omitempty"` // индекс в Task.Sources для image
+
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	Fit string `json:"fit,omitempty"` // fill, fit, stretch
+
+	Text       string  `json:"text,omitempty"`
+	Font       string  `json:"font,omitempty"`
+	FontSize   float64 `json:"font_size,omitempty"`
+	LineHeight float64 `json:"line_height,omitempty"`
+	Color      string  `json:"color,omitempty"`
+	Align      string  `json:"align,omitempty"`
+	VAlign     string  `json:"valign,omitempty"`
+	MaxLines   int     `json:"max_lines,omitempty"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+type TileOptions struct {
+	Layout   string `json:"layout"`    // dzi, xyz
+	TileSize int    `json:"tile_size"` // сторона тайла без перекрытия
+	Overlap  int    `json:"overlap"`   // только для dzi
+	Format   string `json:"format"`    // jpeg, png
+}
+
+// File - дополнительный объект результата, сохраняется под префиксом изображения.
+type File struct {
+	Name        string
+	Data        []byte
+	ContentType string
+}
+
+type TilePyramid struct {
+	Descriptor            []byte
+	DescriptorName        string
+	DescriptorContentType string
+	Files                 []File // тайлы и копия дескриптора
+	Width                 int
+	Height                int
+	Levels                int
+}
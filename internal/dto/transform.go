@@ -0,0 +1,19 @@
+package dto
+
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// TransformOptions задает либо аффинную матрицу, либо четыре угла исходной области.
+type TransformOptions struct {
+	// a, b, c, d, e, f: x' = a*x + b*y + c, y' = d*x + e*y + f
+	Matrix []float64 `json:"matrix,omitempty"`
+	// левый верхний, правый верхний, правый нижний, левый нижний углы области на исходнике
+	Corners []Point `json:"corners,omitempty"`
+
+	Width         int    `json:"width,omitempty"`  // 0 - по преобразованному изображению или по сторонам области
+	Height        int    `json:"height,omitempty"` // 0 - по преобразованному изображению или по сторонам области
+	Interpolation string `json:"interpolation"`    // bilinear, bicubic
+	Fill          string `json:"fill,omitempty"`   // #RRGGBB или #RRGGBBAA; пусто - прозрачный
+}
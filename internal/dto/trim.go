@@ -0,0 +1,6 @@
+package dto
+
+type TrimOptions struct {
+	Tolerance int `json:"tolerance"` // допустимое отклонение канала от цвета рамки, 0..255
+	Padding   int `json:"padding"`   // сколько пикселей рамки оставить после обрезки
+}
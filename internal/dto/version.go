@@ -0,0 +1,7 @@
+package dto
+
+// OperationSpec - операция и ее параметры, которые сохраняются вместе с версией результата.
+type OperationSpec struct {
+	Operation string
+	Params    []byte // JSON
+}
@@ -0,0 +1,11 @@
+package dto
+
+type InvisibleWatermarkOptions struct {
+	Payload string `json:"payload"` // UUID или строка до 16 байт
+}
+
+type WatermarkDetection struct {
+	Found      bool
+	Payload    string
+	Confidence float64 // средняя согласованность голосов по битам, 0..1; только если знак найден
+}
@@ -12,10 +12,18 @@ type Image struct {
 	OriginalKey  string  `json:"original_key"`
 	ProcessedKey *string `json:"processed_key,omitempty"`
 
-	OriginalName string `json:"original_name"`
-	ContentType  string `json:"content_type"`
-	Size         int64  `json:"size"`
-	Status       Status `json:"status"` // pending, processed
+	OriginalName         string  `json:"original_name"`
+	ContentType          string  `json:"content_type"`
+	ProcessedContentType *string `json:"processed_content_type,omitempty"` // может отличаться от оригинала (quantize -> gif)
+	Size                 int64   `json:"size"`
+	Status               Status  `json:"status"` // pending, processed, failed
+
+	Operation     string  `json:"operation,omitempty"`      // пусто у изображений, созданных до появления колонки
+	FailureReason *string `json:"failure_reason,omitempty"` // причина, по которой обработка не удалась
+
+	Metadata Metadata `json:"metadata"`
+
+	CurrentVersionID *uuid.UUID `json:"current_version_id,omitempty"` // nil у изображений, обработанных до появления версий
 
 	CreatedAt   time.Time  `json:"created_at"`
 	ProcessedAt *time.Time `json:"processed_at,omitempty"`
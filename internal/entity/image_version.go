@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImageVersion - неизменяемый результат одной обработки изображения.
+type ImageVersion struct {
+	ID      uuid.UUID `json:"id"`
+	ImageID uuid.UUID `json:"image_id"`
+	Number  int       `json:"number"` // 1, 2, ... в порядке обработки
+
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+
+	Operation string          `json:"operation"`
+	Spec      json.RawMessage `json:"spec"` // параметры операции в том виде, в каком они ушли воркеру
+
+	Metadata Metadata `json:"metadata"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,51 @@
+package entity
+
+// Metadata - вычисленные при обработке сведения об изображении (хранится в jsonb).
+type Metadata struct {
+	Trim  *Rect    `json:"trim,omitempty"`  // область, оставленная операцией trim
+	Tiles *Tiles   `json:"tiles,omitempty"` // пирамида тайлов, построенная операцией tiles
+	Files []string `json:"files,omitempty"` // дополнительные файлы результата (манифест спрайта, иконки)
+
+	Quality *Quality `json:"quality,omitempty"` // оценка качества оригинала
+}
+
+type Rect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type Tiles struct {
+	Layout     string `json:"layout"`
+	Descriptor string `json:"descriptor"` // имя файла дескриптора внутри префикса
+	TileSize   int    `json:"tile_size"`
+	Overlap    int    `json:"overlap"`
+	Format     string `json:"format"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Levels     int    `json:"levels"`
+}
+
+const (
+	QualityGood = "good"
+	QualityFair = "fair"
+	QualityPoor = "poor"
+
+	IssueBlurry        = "blurry"
+	IssueOverexposed   = "overexposed"
+	IssueUnderexposed  = "underexposed"
+	IssueLowResolution = "low_resolution"
+)
+
+type Quality struct {
+	Sharpness         float64  `json:"sharpness"`          // дисперсия лапласиана яркости
+	Brightness        float64  `json:"brightness"`         // средняя яркость, 0..255
+	Contrast          float64  `json:"contrast"`           // стандартное отклонение яркости
+	ClippedShadows    float64  `json:"clipped_shadows"`    // доля пикселей в черном
+	ClippedHighlights float64  `json:"clipped_highlights"` // доля пикселей в белом
+	Width             int      `json:"width"`
+	Height            int      `json:"height"`
+	Grade             string   `json:"grade"` // good, fair, poor
+	Issues            []string `json:"issues,omitempty"`
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	LayerBase = "base" // фиксированное изображение шаблона
+	LayerSlot = "slot" // изображение, которое передается при рендере
+	LayerText = "text" // текстовый блок, текст можно передать при рендере
+)
+
+// Template - шаблон карточки: холст и слои, которые рисуются по порядку.
+type Template struct {
+	ID         uuid.UUID       `json:"id"`
+	Name       string          `json:"name"`
+	Width      int             `json:"width"`
+	Height     int             `json:"height"`
+	Background string          `json:"background"`
+	Layers     []TemplateLayer `json:"layers"` // хранится в jsonb
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TemplateLayer struct {
+	Type    string     `json:"type"`               // base, slot, text
+	Name    string     `json:"name,omitempty"`     // slot, text: по имени передаются значения при рендере
+	ImageID *uuid.UUID `json:"image_id,omitempty"` // base
+
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	Fit string `json:"fit,omitempty"` // base, slot: fill, fit, stretch
+
+	Text       string  `json:"text,omitempty"`        // текст по умолчанию
+	Font       string  `json:"font,omitempty"`        // regular, bold, italic, mono
+	FontSize   float64 `json:"font_size,omitempty"`   //
+	LineHeight float64 `json:"line_height,omitempty"` // множитель кегля
+	Color      string  `json:"color,omitempty"`       //
+	Align      string  `json:"align,omitempty"`       // left, center, right
+	VAlign     string  `json:"valign,omitempty"`      // top, middle, bottom
+	MaxLines   int     `json:"max_lines,omitempty"`   // 0 - сколько поместится по высоте
+}
@@ -2,7 +2,9 @@ package infrastructure
 
 import (
 	"context"
+	"image"
 
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
 	"github.com/andreyxaxa/Image-Processor/internal/entity"
 )
 
@@ -16,5 +18,27 @@ type (
 		Resize(ctx context.Context, contentType string, data []byte, width, height int) ([]byte, error)
 		Thumbnail(ctx context.Context, contentType string, data []byte) ([]byte, error)
 		Watermark(ctx context.Context, contentType string, data []byte, text string) ([]byte, error)
+		Collage(ctx context.Context, contentType string, sources [][]byte, layout dto.CollageLayout) ([]byte, error)
+		Quantize(ctx context.Context, contentType string, data []byte, opts dto.QuantizeOptions) ([]byte, error)
+		Trim(ctx context.Context, contentType string, data []byte, opts dto.TrimOptions) ([]byte, image.Rectangle, error)
+		AutoEnhance(ctx context.Context, contentType string, data []byte, opts dto.EnhanceOptions) ([]byte, error)
+		Rasterize(ctx context.Context, contentType string, data []byte, opts dto.RasterizeOptions) ([]byte, error)
+		Compare(ctx context.Context, a, b []byte, withDiff bool) (dto.Comparison, error)
+		EmbedInvisibleWatermark(ctx context.Context, contentType string, data []byte, payload string) ([]byte, error)
+		DetectInvisibleWatermark(ctx context.Context, data []byte) (dto.WatermarkDetection, error)
+		Tiles(ctx context.Context, data []byte, opts dto.TileOptions) (dto.TilePyramid, error)
+		Sprite(ctx context.Context, sources [][]byte, opts dto.SpriteOptions) ([]byte, []byte, error)
+		Favicon(ctx context.Context, data []byte) ([]byte, []dto.File, error)
+		RemoveBackground(ctx context.Context, data []byte, opts dto.RemoveBackgroundOptions) ([]byte, error)
+		Transform(ctx context.Context, contentType string, data []byte, opts dto.TransformOptions) ([]byte, error)
+		TransformSize(ctx context.Context, width, height int, opts dto.TransformOptions) (int, int, error)
+		Annotate(ctx context.Context, contentType string, data []byte, opts dto.AnnotateOptions) ([]byte, error)
+		RenderTemplate(ctx context.Context, contentType string, sources [][]byte, opts dto.TemplateRender) ([]byte, error)
+		AssessQuality(ctx context.Context, data []byte) (entity.Quality, error)
+		WriteMetadata(ctx context.Context, data []byte, override dto.OutputMetadata) ([]byte, error)
+	}
+
+	URLFetcher interface {
+		Fetch(ctx context.Context, rawURL string) (dto.RemoteFile, error)
 	}
 )
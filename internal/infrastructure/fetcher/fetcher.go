@@ -0,0 +1,135 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+)
+
+const (
+	_defaultTimeout      = 10 * time.Second
+	_defaultMaxSize      = 10 << 20
+	_defaultMaxRedirects = 3
+
+	_userAgent = "Image-Processor/1.0"
+)
+
+var (
+	errTooManyRedirects = errors.New("too many redirects")
+	errBadStatus        = errors.New("unexpected response status")
+)
+
+// Fetcher скачивает файлы по URL с защитой от SSRF: запросы во внутренние сети запрещены
+// на уровне соединения, размер, время и число редиректов ограничены.
+type Fetcher struct {
+	timeout      time.Duration
+	maxSize      int64
+	maxRedirects int
+	allowedHosts []string
+
+	client *http.Client
+}
+
+func New(opts ...Option) *Fetcher {
+	f := &Fetcher{
+		timeout:      _defaultTimeout,
+		maxSize:      _defaultMaxSize,
+		maxRedirects: _defaultMaxRedirects,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	dialer := &net.Dialer{
+		Timeout: f.timeout,
+		Control: dialControl,
+	}
+
+	f.client = &http.Client{
+		Timeout: f.timeout,
+		Transport: &http.Transport{
+			// прокси из окружения обошел бы проверку адреса в dialControl
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   f.timeout,
+			ResponseHeaderTimeout: f.timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > f.maxRedirects {
+				return errTooManyRedirects
+			}
+
+			return f.checkURL(req.URL)
+		},
+	}
+
+	return f
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (dto.RemoteFile, error) {
+	// 1. разбираем и проверяем URL до обращения в сеть
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return dto.RemoteFile{}, fmt.Errorf("%w: %s", errs.ErrURLNotAllowed, err)
+	}
+
+	if err = f.checkURL(u); err != nil {
+		return dto.RemoteFile{}, fmt.Errorf("Fetcher - Fetch - f.checkURL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return dto.RemoteFile{}, fmt.Errorf("Fetcher - Fetch - http.NewRequestWithContext: %w", err)
+	}
+	req.Header.Set("User-Agent", _userAgent)
+	req.Header.Set("Accept", "image/*")
+
+	// 2. выполняем запрос, редиректы проверяются в CheckRedirect
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return dto.RemoteFile{}, fmt.Errorf("Fetcher - Fetch - f.client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return dto.RemoteFile{}, fmt.Errorf("%w: %d", errBadStatus, resp.StatusCode)
+	}
+
+	// 3. отказываем сразу, если сервер заявил слишком большой файл
+	if resp.ContentLength > f.maxSize {
+		return dto.RemoteFile{}, errs.ErrRemoteTooLarge
+	}
+
+	// 4. читаем не больше лимита + 1 байт, чтобы отличить файл ровно в лимит от превышения
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxSize+1))
+	if err != nil {
+		return dto.RemoteFile{}, fmt.Errorf("Fetcher - Fetch - io.ReadAll: %w", err)
+	}
+
+	if int64(len(data)) > f.maxSize {
+		return dto.RemoteFile{}, errs.ErrRemoteTooLarge
+	}
+
+	name := path.Base(resp.Request.URL.Path)
+	if name == "." || name == "/" {
+		name = ""
+	}
+
+	return dto.RemoteFile{
+		Data:        data,
+		Name:        name,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
@@ -0,0 +1,110 @@
+package fetcher
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+)
+
+// сети, не входящие в IsPrivate/IsLoopback/IsLinkLocal*, но тоже недоступные снаружи
+var deniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "этот" хост
+	netip.MustParsePrefix("100.64.0.0/10"),  // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // тестирование производительности
+	netip.MustParsePrefix("240.0.0.0/4"),    // зарезервировано, включая broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64 - может вести во внутреннюю IPv4-сеть
+	netip.MustParsePrefix("64:ff9b:1::/48"), // локальный NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4 - внутри IPv4-адрес
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+}
+
+// publicAddr проверяет, что адрес публичный: не loopback, не частная сеть, не link-local и т.п.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+
+	for _, p := range deniedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dialControl вызывается для каждого соединения уже после DNS-резолва, поэтому проверяет
+// именно тот адрес, к которому идет подключение - подмена DNS между проверкой и запросом не поможет.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	if network != "tcp4" && network != "tcp6" {
+		return fmt.Errorf("%w: network %s", errs.ErrURLNotAllowed, network)
+	}
+
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errs.ErrURLNotAllowed, address)
+	}
+
+	if !publicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", errs.ErrURLNotAllowed, addrPort.Addr())
+	}
+
+	return nil
+}
+
+// checkURL проверяет схему, учетные данные и хост URL до запроса и на каждом редиректе.
+func (f *Fetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", errs.ErrURLNotAllowed)
+	}
+
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in URL are not allowed", errs.ErrURLNotAllowed)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("%w: host is required", errs.ErrURLNotAllowed)
+	}
+
+	// IP-литерал проверяем сразу, чтобы не ходить в сеть ради заведомого отказа
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddr(addr) {
+		return fmt.Errorf("%w: %s", errs.ErrURLNotAllowed, addr)
+	}
+
+	if !f.hostAllowed(host) {
+		return fmt.Errorf("%w: host %s is not in the allowlist", errs.ErrURLNotAllowed, host)
+	}
+
+	return nil
+}
+
+func (f *Fetcher) hostAllowed(host string) bool {
+	if len(f.allowedHosts) == 0 {
+		return true
+	}
+
+	host = strings.TrimSuffix(host, ".")
+	for _, allowed := range f.allowedHosts {
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,42 @@
+package fetcher
+
+import (
+	"strings"
+	"time"
+)
+
+type Option func(*Fetcher)
+
+// Timeout ограничивает весь запрос: соединение, редиректы и чтение тела.
+func Timeout(timeout time.Duration) Option {
+	return func(f *Fetcher) {
+		f.timeout = timeout
+	}
+}
+
+// MaxSize ограничивает размер тела ответа в байтах.
+func MaxSize(size int64) Option {
+	return func(f *Fetcher) {
+		f.maxSize = size
+	}
+}
+
+// MaxRedirects ограничивает число редиректов, 0 - редиректы запрещены.
+func MaxRedirects(n int) Option {
+	return func(f *Fetcher) {
+		f.maxRedirects = n
+	}
+}
+
+// AllowedHosts - разрешенные хосты: точное имя или *.example.com для поддоменов.
+// Пустой список разрешает любой публичный хост.
+func AllowedHosts(hosts []string) Option {
+	return func(f *Fetcher) {
+		f.allowedHosts = make([]string, 0, len(hosts))
+		for _, h := range hosts {
+			if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+				f.allowedHosts = append(f.allowedHosts, h)
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	ShapeRect    = "rect"
+	ShapeEllipse = "ellipse"
+	ShapeLine    = "line"
+	ShapeArrow   = "arrow"
+	ShapeText    = "text"
+
+	// наконечник стрелки относительно толщины линии
+	arrowHeadScale = 4.0
+	arrowHeadMin   = 10.0
+)
+
+// Annotate рисует примитивы поверх изображения в порядке их следования.
+func (p *ImageProcessor) Annotate(ctx context.Context, contentType string, data []byte, opts dto.AnnotateOptions) ([]byte, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Annotate - decodeImage: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Annotate: %w", err)
+	}
+
+	// растеризатор работает с предумноженной альфой
+	b := img.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), img, b.Min, draw.Src)
+
+	scanner := rasterx.NewScannerGV(b.Dx(), b.Dy(), canvas, canvas.Bounds())
+	filler := rasterx.NewFiller(b.Dx(), b.Dy(), scanner)
+	stroker := rasterx.NewStroker(b.Dx(), b.Dy(), scanner)
+
+	for _, shape := range opts.Shapes {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("ImageProcessor - Annotate: %w", err)
+		}
+
+		if err := drawShape(canvas, filler, stroker, shape); err != nil {
+			return nil, fmt.Errorf("ImageProcessor - Annotate - drawShape: %w", err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Annotate: %w", err)
+	}
+
+	res, err := p.encodeImage(canvas, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Annotate - encodeImage: %w", err)
+	}
+
+	return res, nil
+}
+
+func drawShape(canvas *image.RGBA, filler *rasterx.Filler, stroker *rasterx.Stroker, shape dto.Shape) error {
+	stroke, err := ParseHexColor(shape.Color)
+	if err != nil {
+		return err
+	}
+
+	var fill *color.NRGBA
+	if shape.Fill != "" {
+		c, err := ParseHexColor(shape.Fill)
+		if err != nil {
+			return err
+		}
+		fill = &c
+	}
+
+	width := fixed.Int26_6(shape.StrokeWidth * 64)
+	stroker.SetStroke(width, 4<<6, rasterx.ButtCap, nil, rasterx.FlatGap, rasterx.MiterClip)
+
+	switch shape.Type {
+	case ShapeRect:
+		if fill != nil {
+			rasterx.AddRect(shape.X, shape.Y, shape.X+shape.Width, shape.Y+shape.Height, 0, filler)
+			paint(filler, *fill)
+		}
+		rasterx.AddRect(shape.X, shape.Y, shape.X+shape.Width, shape.Y+shape.Height, 0, stroker)
+		paint(stroker, stroke)
+	case ShapeEllipse:
+		rx, ry := shape.Width/2, shape.Height/2
+		if fill != nil {
+			rasterx.AddEllipse(shape.X+rx, shape.Y+ry, rx, ry, 0, filler)
+			paint(filler, *fill)
+		}
+		rasterx.AddEllipse(shape.X+rx, shape.Y+ry, rx, ry, 0, stroker)
+		paint(stroker, stroke)
+	case ShapeLine:
+		stroker.SetStroke(width, 4<<6, rasterx.RoundCap, nil, rasterx.RoundGap, rasterx.Round)
+		segment(stroker, shape.X, shape.Y, shape.X2, shape.Y2)
+		paint(stroker, stroke)
+	case ShapeArrow:
+		drawArrow(filler, stroker, shape, stroke)
+	case ShapeText:
+		return drawLabel(canvas, filler, shape, stroke, fill)
+	default:
+		return fmt.Errorf("unknown shape %q", shape.Type)
+	}
+
+	return nil
+}
+
+// drawArrow рисует линию, укороченную на длину наконечника, и залитый треугольник на конце.
+func drawArrow(filler *rasterx.Filler, stroker *rasterx.Stroker, shape dto.Shape, c color.NRGBA) {
+	dx, dy := shape.X2-shape.X, shape.Y2-shape.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	ux, uy := dx/length, dy/length
+
+	head := math.Min(math.Max(shape.StrokeWidth*arrowHeadScale, arrowHeadMin), length)
+	baseX, baseY := shape.X2-ux*head, shape.Y2-uy*head
+
+	if head < length {
+		stroker.SetStroke(fixed.Int26_6(shape.StrokeWidth*64), 4<<6, rasterx.RoundCap, rasterx.ButtCap, rasterx.RoundGap, rasterx.Round)
+		// заходим под наконечник, чтобы не было щели
+		segment(stroker, shape.X, shape.Y, baseX+ux*head/4, baseY+uy*head/4)
+		paint(stroker, c)
+	}
+
+	// половина ширины наконечника - 0.6 его длины
+	nx, ny := -uy*head*0.6, ux*head*0.6
+	filler.Start(point(shape.X2, shape.Y2))
+	filler.Line(point(baseX+nx, baseY+ny))
+	filler.Line(point(baseX-nx, baseY-ny))
+	filler.Stop(true)
+	paint(filler, c)
+}
+
+// drawLabel рисует текст с левым верхним углом в (X, Y) на необязательной подложке.
+func drawLabel(canvas *image.RGBA, filler *rasterx.Filler, shape dto.Shape, c color.NRGBA, fill *color.NRGBA) error {
+	face, err := newFace(FontRegular, shape.FontSize)
+	if err != nil {
+		return fmt.Errorf("newFace: %w", err)
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	ascent := float64(metrics.Ascent) / 64
+	height := float64(metrics.Ascent+metrics.Descent) / 64
+	width := float64(font.MeasureString(face, shape.Text)) / 64
+
+	// подложка шире текста на четверть кегля с каждой стороны
+	pad := 0.0
+	if fill != nil {
+		pad = shape.FontSize / 4
+		rasterx.AddRect(shape.X, shape.Y, shape.X+width+2*pad, shape.Y+height+2*pad, 0, filler)
+		paint(filler, *fill)
+	}
+
+	d := &font.Drawer{
+		Dst:  canvas,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(int(math.Round(shape.X+pad)), int(math.Round(shape.Y+pad+ascent))),
+	}
+	d.DrawString(shape.Text)
+
+	return nil
+}
+
+func segment(a rasterx.Adder, x1, y1, x2, y2 float64) {
+	a.Start(point(x1, y1))
+	a.Line(point(x2, y2))
+	a.Stop(false)
+}
+
+// paint закрашивает накопленный путь и очищает его для следующей фигуры.
+func paint(s rasterx.Scanner, c color.NRGBA) {
+	s.SetColor(c)
+	s.Draw()
+	s.Clear()
+}
+
+func point(x, y float64) fixed.Point26_6 {
+	return fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(y * 64)}
+}
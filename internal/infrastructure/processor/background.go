@@ -0,0 +1,160 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sort"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+)
+
+// RemoveBackground делает прозрачными пиксели цвета фона и смягчает край маски.
+// Результат всегда png.
+func (p *ImageProcessor) RemoveBackground(ctx context.Context, data []byte, opts dto.RemoveBackgroundOptions) ([]byte, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - RemoveBackground - decodeImage: %w", err)
+	}
+
+	src := imaging.Clone(img)
+
+	// 1. цвет фона: заданный или медиана пикселей по периметру
+	var key []uint8
+	if opts.Color != "" {
+		c, err := ParseHexColor(opts.Color)
+		if err != nil {
+			return nil, fmt.Errorf("ImageProcessor - RemoveBackground - ParseHexColor: %w", err)
+		}
+		key = []uint8{c.R, c.G, c.B, 0xff}
+	} else {
+		key = borderMedian(src)
+	}
+
+	// 2. маска: 0 - фон, 255 - объект
+	mask := keyMask(src, key, opts.Tolerance, opts.Contiguous)
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - RemoveBackground: %w", err)
+	}
+
+	// 3. мягкий край только внутрь объекта, чтобы не вернуть ореол цвета фона
+	if opts.Feather > 0 {
+		blurred := imaging.Blur(mask, float64(opts.Feather)/2)
+		for i := range mask.Pix {
+			mask.Pix[i] = min(mask.Pix[i], blurred.Pix[i*4])
+		}
+	}
+
+	// 4. альфа и очистка полупрозрачного края от примеси цвета фона
+	forEachPixel(src, func(i int, px []uint8) {
+		a := min(px[3], mask.Pix[i])
+		if a > 0 && a < 0xff {
+			k := float64(a) / 0xff
+			for ch := 0; ch < 3; ch++ {
+				px[ch] = clamp8((float64(px[ch]) - (1-k)*float64(key[ch])) / k)
+			}
+		}
+		px[3] = a
+	})
+
+	res, err := p.encodeImage(src, "image/png")
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - RemoveBackground - encodeImage: %w", err)
+	}
+
+	return res, nil
+}
+
+// keyMask помечает фоном пиксели, близкие к key. С contiguous фоном считаются
+// только области, связанные с краями изображения (заливка от периметра).
+func keyMask(img *image.NRGBA, key []uint8, tolerance int, contiguous bool) *image.Gray {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	mask := image.NewGray(image.Rect(0, 0, w, h))
+	candidate := make([]bool, w*h)
+	forEachPixel(img, func(i int, px []uint8) {
+		candidate[i] = px[3] == 0 || colorDistance([]uint8{px[0], px[1], px[2], 0xff}, key) <= tolerance
+		mask.Pix[i] = 0xff
+	})
+
+	if !contiguous {
+		for i, c := range candidate {
+			if c {
+				mask.Pix[i] = 0
+			}
+		}
+		return mask
+	}
+
+	// заливка от пикселей периметра
+	stack := make([]int, 0, 2*(w+h))
+	push := func(i int) {
+		if candidate[i] && mask.Pix[i] != 0 {
+			mask.Pix[i] = 0
+			stack = append(stack, i)
+		}
+	}
+
+	for x := 0; x < w; x++ {
+		push(x)
+		push((h-1)*w + x)
+	}
+	for y := 0; y < h; y++ {
+		push(y * w)
+		push(y*w + w - 1)
+	}
+
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		x, y := i%w, i/w
+		if x > 0 {
+			push(i - 1)
+		}
+		if x < w-1 {
+			push(i + 1)
+		}
+		if y > 0 {
+			push(i - w)
+		}
+		if y < h-1 {
+			push(i + w)
+		}
+	}
+
+	return mask
+}
+
+// borderMedian - поканальная медиана пикселей по периметру изображения.
+func borderMedian(img *image.NRGBA) []uint8 {
+	b := img.Bounds()
+
+	var channels [3][]uint8
+	add := func(x, y int) {
+		px := img.Pix[img.PixOffset(x, y):][:4]
+		for ch := range channels {
+			channels[ch] = append(channels[ch], px[ch])
+		}
+	}
+
+	for x := b.Min.X; x < b.Max.X; x++ {
+		add(x, b.Min.Y)
+		add(x, b.Max.Y-1)
+	}
+	for y := b.Min.Y + 1; y < b.Max.Y-1; y++ {
+		add(b.Min.X, y)
+		add(b.Max.X-1, y)
+	}
+
+	key := []uint8{0, 0, 0, 0xff}
+	for ch, values := range channels {
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		key[ch] = values[len(values)/2]
+	}
+
+	return key
+}
@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+)
+
+const (
+	fitContain = "fit"
+	fitStretch = "stretch"
+)
+
+func (p *ImageProcessor) Collage(ctx context.Context, contentType string, sources [][]byte, layout dto.CollageLayout) ([]byte, error) {
+	bg, err := ParseHexColor(layout.Background)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Collage - ParseHexColor: %w", err)
+	}
+
+	width := layout.Columns*layout.CellWidth + (layout.Columns+1)*layout.Gap
+	height := layout.Rows*layout.CellHeight + (layout.Rows+1)*layout.Gap
+
+	canvas := imaging.New(width, height, bg)
+
+	for i, src := range sources {
+		if i >= layout.Rows*layout.Columns {
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("ImageProcessor - Collage: %w", err)
+		}
+
+		img, err := decodeImage(src)
+		if err != nil {
+			return nil, fmt.Errorf("ImageProcessor - Collage - decodeImage: %w", err)
+		}
+
+		cell := fitCell(img, layout.CellWidth, layout.CellHeight, layout.Fit)
+
+		// ячейки заполняются построчно, картинка центрируется внутри ячейки
+		row, col := i/layout.Columns, i%layout.Columns
+		x := layout.Gap + col*(layout.CellWidth+layout.Gap) + (layout.CellWidth-cell.Bounds().Dx())/2
+		y := layout.Gap + row*(layout.CellHeight+layout.Gap) + (layout.CellHeight-cell.Bounds().Dy())/2
+
+		draw.Draw(canvas, cell.Bounds().Add(image.Pt(x, y)), cell, cell.Bounds().Min, draw.Over)
+	}
+
+	res, err := p.encodeImage(canvas, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Collage - encodeImage: %w", err)
+	}
+
+	return res, nil
+}
+
+func fitCell(img image.Image, width, height int, fit string) *image.NRGBA {
+	switch fit {
+	case fitContain:
+		return imaging.Fit(img, width, height, imaging.Lanczos)
+	case fitStretch:
+		return imaging.Resize(img, width, height, imaging.Lanczos)
+	default: // fill
+		return imaging.Fill(img, width, height, imaging.Center, imaging.Lanczos)
+	}
+}
@@ -0,0 +1,32 @@
+package processor
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// ParseHexColor разбирает цвет в формате #RRGGBB или #RRGGBBAA.
+func ParseHexColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("ImageProcessor - ParseHexColor: invalid color %q", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("ImageProcessor - ParseHexColor - strconv.ParseUint: %w", err)
+	}
+
+	if len(hex) == 6 {
+		v = v<<8 | 0xff
+	}
+
+	return color.NRGBA{
+		R: uint8(v >> 24),
+		G: uint8(v >> 16),
+		B: uint8(v >> 8),
+		A: uint8(v),
+	}, nil
+}
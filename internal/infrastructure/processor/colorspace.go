@@ -0,0 +1,145 @@
+package processor
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/disintegration/imaging"
+)
+
+// toSRGB приводит декодированное изображение к 8-битному NRGBA в sRGB:
+// CMYK и изображения с ICC-профилем переводятся через профиль,
+// 16-битные каналы округляются, а не обрезаются.
+func toSRGB(img image.Image, profile *iccProfile) *image.NRGBA {
+	// профиль другого цветового пространства применить нельзя
+	if profile != nil && !profileMatches(img, profile) {
+		profile = nil
+	}
+
+	if src, ok := img.(*image.CMYK); ok {
+		return cmykToSRGB(src, profile)
+	}
+
+	read, ok := wideReader(img)
+	if !ok {
+		dst := imaging.Clone(img)
+		if profile == nil {
+			return dst
+		}
+		read = func(x, y int) (r, g, b, a uint16) {
+			px := dst.Pix[y*dst.Stride+x*4:]
+			return uint16(px[0]) * 257, uint16(px[1]) * 257, uint16(px[2]) * 257, uint16(px[3]) * 257
+		}
+	}
+
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	var in [3]float64
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			r, g, b, a := read(x, y)
+			px := dst.Pix[y*dst.Stride+x*4:]
+
+			if profile == nil {
+				px[0], px[1], px[2] = narrow16(r), narrow16(g), narrow16(b)
+			} else {
+				in[0], in[1], in[2] = float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff
+				px[0], px[1], px[2] = profile.rgb(in[:])
+			}
+			px[3] = narrow16(a)
+		}
+	}
+
+	return dst
+}
+
+func profileMatches(img image.Image, profile *iccProfile) bool {
+	switch img.(type) {
+	case *image.CMYK:
+		return profile.colorSpace == "CMYK"
+	case *image.Gray, *image.Gray16:
+		return profile.colorSpace == "GRAY"
+	default:
+		return profile.colorSpace == "RGB "
+	}
+}
+
+// wideReader возвращает чтение неумноженных 16-битных каналов для 16-битных изображений.
+// Координаты отсчитываются от левого верхнего угла.
+func wideReader(img image.Image) (func(x, y int) (r, g, b, a uint16), bool) {
+	switch src := img.(type) {
+	case *image.NRGBA64:
+		return func(x, y int) (r, g, b, a uint16) {
+			c := src.NRGBA64At(src.Rect.Min.X+x, src.Rect.Min.Y+y)
+			return c.R, c.G, c.B, c.A
+		}, true
+	case *image.RGBA64:
+		return func(x, y int) (r, g, b, a uint16) {
+			c := color.NRGBA64Model.Convert(src.RGBA64At(src.Rect.Min.X+x, src.Rect.Min.Y+y)).(color.NRGBA64)
+			return c.R, c.G, c.B, c.A
+		}, true
+	case *image.Gray16:
+		return func(x, y int) (r, g, b, a uint16) {
+			v := src.Gray16At(src.Rect.Min.X+x, src.Rect.Min.Y+y).Y
+			return v, v, v, 0xffff
+		}, true
+	}
+
+	return nil, false
+}
+
+func cmykToSRGB(src *image.CMYK, profile *iccProfile) *image.NRGBA {
+	bounds := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	// в фотографиях много повторяющихся цветов - запоминаем результат
+	cache := make(map[uint32][3]uint8)
+
+	var in [4]float64
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := src.CMYKAt(bounds.Min.X+x, bounds.Min.Y+y)
+			px := dst.Pix[y*dst.Stride+x*4:]
+			px[3] = 0xff
+
+			if profile == nil {
+				// без профиля - простая формула без учета красок
+				r, g, b, _ := c.RGBA()
+				px[0], px[1], px[2] = narrow16(uint16(r)), narrow16(uint16(g)), narrow16(uint16(b))
+				continue
+			}
+
+			key := uint32(c.C)<<24 | uint32(c.M)<<16 | uint32(c.Y)<<8 | uint32(c.K)
+			rgb, ok := cache[key]
+			if !ok {
+				in[0], in[1], in[2], in[3] = float64(c.C)/0xff, float64(c.M)/0xff, float64(c.Y)/0xff, float64(c.K)/0xff
+				rgb[0], rgb[1], rgb[2] = profile.rgb(in[:])
+				cache[key] = rgb
+			}
+			px[0], px[1], px[2] = rgb[0], rgb[1], rgb[2]
+		}
+	}
+
+	return dst
+}
+
+// narrow16 переводит 16-битное значение канала в 8-битное с округлением.
+func narrow16(v uint16) uint8 {
+	return uint8((uint32(v)*0xff + 0x7fff) / 0xffff)
+}
+
+// flatten накладывает полупрозрачное изображение на фон для форматов без альфа-канала.
+func flatten(img image.Image, background color.Color) image.Image {
+	if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+		return img
+	}
+
+	bg := color.NRGBAModel.Convert(background).(color.NRGBA)
+	bg.A = 0xff
+
+	bounds := img.Bounds()
+	canvas := imaging.New(bounds.Dx(), bounds.Dy(), bg)
+
+	return imaging.Overlay(canvas, img, image.Pt(0, 0), 1)
+}
@@ -0,0 +1,197 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+)
+
+const (
+	ssimWindow = 8
+	ssimStep   = 4
+
+	// PSNR одинаковых изображений бесконечен, ограничиваем для сериализации
+	maxPSNR = 100.0
+)
+
+var (
+	ssimC1 = math.Pow(0.01*255, 2)
+	ssimC2 = math.Pow(0.03*255, 2)
+)
+
+// Compare считает SSIM (по яркости) и PSNR (по RGB) между двумя изображениями.
+// Большее изображение предварительно приводится к размеру меньшего.
+func (p *ImageProcessor) Compare(ctx context.Context, a, b []byte, withDiff bool) (dto.Comparison, error) {
+	imgA, err := decodeImage(a)
+	if err != nil {
+		return dto.Comparison{}, fmt.Errorf("ImageProcessor - Compare - decodeImage: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return dto.Comparison{}, fmt.Errorf("ImageProcessor - Compare: %w", err)
+	}
+
+	imgB, err := decodeImage(b)
+	if err != nil {
+		return dto.Comparison{}, fmt.Errorf("ImageProcessor - Compare - decodeImage: %w", err)
+	}
+
+	na, nb := sameSize(imgA, imgB)
+
+	if err := ctx.Err(); err != nil {
+		return dto.Comparison{}, fmt.Errorf("ImageProcessor - Compare: %w", err)
+	}
+
+	res := dto.Comparison{
+		SSIM:   ssim(na, nb),
+		PSNR:   psnr(na, nb),
+		Width:  na.Bounds().Dx(),
+		Height: na.Bounds().Dy(),
+	}
+
+	if err := ctx.Err(); err != nil {
+		return dto.Comparison{}, fmt.Errorf("ImageProcessor - Compare: %w", err)
+	}
+
+	if withDiff {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, diffHeatmap(na, nb)); err != nil {
+			return dto.Comparison{}, fmt.Errorf("ImageProcessor - Compare - png.Encode: %w", err)
+		}
+		res.Diff = buf.Bytes()
+	}
+
+	return res, nil
+}
+
+func sameSize(a, b image.Image) (*image.NRGBA, *image.NRGBA) {
+	ba, bb := a.Bounds(), b.Bounds()
+
+	switch {
+	case ba.Dx() == bb.Dx() && ba.Dy() == bb.Dy():
+		return imaging.Clone(a), imaging.Clone(b)
+	case ba.Dx()*ba.Dy() > bb.Dx()*bb.Dy():
+		return imaging.Resize(a, bb.Dx(), bb.Dy(), imaging.Lanczos), imaging.Clone(b)
+	default:
+		return imaging.Clone(a), imaging.Resize(b, ba.Dx(), ba.Dy(), imaging.Lanczos)
+	}
+}
+
+// ssim - средний SSIM по окнам ssimWindow x ssimWindow с шагом ssimStep.
+func ssim(a, b *image.NRGBA) float64 {
+	w, h := a.Bounds().Dx(), a.Bounds().Dy()
+	ya, yb := lumaPlane(a), lumaPlane(b)
+
+	win := min(ssimWindow, w, h)
+
+	var sum float64
+	var windows int
+
+	for y0 := 0; y0+win <= h; y0 += ssimStep {
+		for x0 := 0; x0+win <= w; x0 += ssimStep {
+			var sa, sb, saa, sbb, sab float64
+			for y := y0; y < y0+win; y++ {
+				for x := x0; x < x0+win; x++ {
+					va, vb := ya[y*w+x], yb[y*w+x]
+					sa += va
+					sb += vb
+					saa += va * va
+					sbb += vb * vb
+					sab += va * vb
+				}
+			}
+
+			n := float64(win * win)
+			ma, mb := sa/n, sb/n
+			varA := saa/n - ma*ma
+			varB := sbb/n - mb*mb
+			cov := sab/n - ma*mb
+
+			sum += ((2*ma*mb + ssimC1) * (2*cov + ssimC2)) /
+				((ma*ma + mb*mb + ssimC1) * (varA + varB + ssimC2))
+			windows++
+		}
+	}
+
+	if windows == 0 {
+		return 1
+	}
+
+	return sum / float64(windows)
+}
+
+func psnr(a, b *image.NRGBA) float64 {
+	var sum float64
+	var n int
+
+	for i := 0; i < len(a.Pix); i += 4 {
+		for ch := 0; ch < 3; ch++ {
+			d := float64(a.Pix[i+ch]) - float64(b.Pix[i+ch])
+			sum += d * d
+			n++
+		}
+	}
+
+	if n == 0 || sum == 0 {
+		return maxPSNR
+	}
+
+	mse := sum / float64(n)
+
+	return math.Min(10*math.Log10(255*255/mse), maxPSNR)
+}
+
+func lumaPlane(img *image.NRGBA) []float64 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	plane := make([]float64, w*h)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*img.Stride + x*4
+			plane[y*w+x] = 0.299*float64(img.Pix[i]) + 0.587*float64(img.Pix[i+1]) + 0.114*float64(img.Pix[i+2])
+		}
+	}
+
+	return plane
+}
+
+// diffHeatmap раскрашивает попиксельную разницу: черный - совпадает, синий -> красный -> желтый - сильнее отличается.
+func diffHeatmap(a, b *image.NRGBA) *image.NRGBA {
+	bounds := a.Bounds()
+	heat := image.NewNRGBA(bounds)
+
+	for i := 0; i < len(a.Pix); i += 4 {
+		d := colorDistance(a.Pix[i:i+4], b.Pix[i:i+4])
+		c := heatColor(float64(d) / 255)
+		heat.Pix[i] = c.R
+		heat.Pix[i+1] = c.G
+		heat.Pix[i+2] = c.B
+		heat.Pix[i+3] = 255
+	}
+
+	return heat
+}
+
+func heatColor(v float64) color.NRGBA {
+	// усиливаем малые отличия, чтобы они были заметны
+	v = math.Sqrt(v)
+
+	switch {
+	case v < 1.0/3:
+		t := v * 3
+		return color.NRGBA{B: clamp8(255 * t), A: 255}
+	case v < 2.0/3:
+		t := (v - 1.0/3) * 3
+		return color.NRGBA{R: clamp8(255 * t), B: clamp8(255 * (1 - t)), A: 255}
+	default:
+		t := (v - 2.0/3) * 3
+		return color.NRGBA{R: 255, G: clamp8(255 * t), A: 255}
+	}
+}
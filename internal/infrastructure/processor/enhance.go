@@ -0,0 +1,207 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// доля самых темных/светлых пикселей, отсекаемая при растяжении уровней
+	levelsClip = 0.005
+
+	// ограничение коэффициентов баланса белого, чтобы не "перекрашивать" монохромные кадры
+	minWhiteBalanceGain = 0.5
+	maxWhiteBalanceGain = 2.0
+)
+
+func (p *ImageProcessor) AutoEnhance(ctx context.Context, contentType string, data []byte, opts dto.EnhanceOptions) ([]byte, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - AutoEnhance - decodeImage: %w", err)
+	}
+
+	dst := imaging.Clone(img)
+
+	// 1. баланс белого по модели "серого мира"
+	if opts.WhiteBalance {
+		grayWorld(dst)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - AutoEnhance: %w", err)
+	}
+
+	// 2. растяжение уровней
+	autoLevels(dst)
+
+	// 3. выравнивание гистограммы яркости
+	if opts.Equalize && opts.Strength > 0 {
+		equalize(dst, opts.Strength)
+	}
+
+	res, err := p.encodeImage(dst, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - AutoEnhance - encodeImage: %w", err)
+	}
+
+	return res, nil
+}
+
+// grayWorld масштабирует каналы так, чтобы средний цвет изображения стал серым.
+func grayWorld(img *image.NRGBA) {
+	var sum [3]float64
+	var n float64
+
+	forEachOpaque(img, func(px []uint8) {
+		sum[0] += float64(px[0])
+		sum[1] += float64(px[1])
+		sum[2] += float64(px[2])
+		n++
+	})
+
+	if n == 0 {
+		return
+	}
+
+	gray := (sum[0] + sum[1] + sum[2]) / 3
+
+	var luts [3][256]uint8
+	for ch := range luts {
+		gain := 1.0
+		if sum[ch] > 0 {
+			gain = math.Min(math.Max(gray/sum[ch], minWhiteBalanceGain), maxWhiteBalanceGain)
+		}
+		for v := range luts[ch] {
+			luts[ch][v] = clamp8(float64(v) * gain)
+		}
+	}
+
+	applyLUT(img, luts)
+}
+
+// autoLevels линейно растягивает яркость, отсекая levelsClip самых темных и светлых пикселей.
+// Одна кривая применяется ко всем каналам, чтобы не сдвигать оттенки.
+func autoLevels(img *image.NRGBA) {
+	hist, total := lumaHistogram(img)
+	if total == 0 {
+		return
+	}
+
+	clip := int(float64(total) * levelsClip)
+
+	lo, acc := 0, 0
+	for lo < 255 {
+		acc += hist[lo]
+		if acc > clip {
+			break
+		}
+		lo++
+	}
+
+	hi, acc := 255, 0
+	for hi > 0 {
+		acc += hist[hi]
+		if acc > clip {
+			break
+		}
+		hi--
+	}
+
+	if hi <= lo {
+		return
+	}
+
+	var lut [256]uint8
+	scale := 255 / float64(hi-lo)
+	for v := range lut {
+		lut[v] = clamp8(float64(v-lo) * scale)
+	}
+
+	applyLUT(img, [3][256]uint8{lut, lut, lut})
+}
+
+// equalize выравнивает гистограмму яркости и смешивает результат с исходником в пропорции strength.
+func equalize(img *image.NRGBA, strength float64) {
+	hist, total := lumaHistogram(img)
+	if total == 0 {
+		return
+	}
+
+	// функция распределения -> новая яркость
+	var mapping [256]float64
+	cdf, cdfMin := 0, -1
+	for v := range hist {
+		cdf += hist[v]
+		if cdfMin < 0 && cdf > 0 {
+			cdfMin = cdf
+		}
+		if total > cdfMin {
+			mapping[v] = float64(cdf-cdfMin) / float64(total-cdfMin) * 255
+		} else {
+			mapping[v] = float64(v)
+		}
+	}
+
+	forEachOpaque(img, func(px []uint8) {
+		y := luma(px)
+		delta := (mapping[y] - float64(y)) * strength
+		px[0] = clamp8(float64(px[0]) + delta)
+		px[1] = clamp8(float64(px[1]) + delta)
+		px[2] = clamp8(float64(px[2]) + delta)
+	})
+}
+
+func lumaHistogram(img *image.NRGBA) ([256]int, int) {
+	var hist [256]int
+	total := 0
+
+	forEachOpaque(img, func(px []uint8) {
+		hist[luma(px)]++
+		total++
+	})
+
+	return hist, total
+}
+
+func applyLUT(img *image.NRGBA, luts [3][256]uint8) {
+	forEachOpaque(img, func(px []uint8) {
+		px[0] = luts[0][px[0]]
+		px[1] = luts[1][px[1]]
+		px[2] = luts[2][px[2]]
+	})
+}
+
+// forEachOpaque вызывает f для каждого непрозрачного пикселя (срез R, G, B, A).
+func forEachOpaque(img *image.NRGBA, f func(px []uint8)) {
+	b := img.Bounds()
+	for y := 0; y < b.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+b.Dx()*4]
+		for x := 0; x < len(row); x += 4 {
+			if row[x+3] == 0 {
+				continue
+			}
+			f(row[x : x+4])
+		}
+	}
+}
+
+// luma - яркость по Rec. 601.
+func luma(px []uint8) uint8 {
+	return uint8((299*int(px[0]) + 587*int(px[1]) + 114*int(px[2]) + 500) / 1000)
+}
+
+func clamp8(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+
+	return uint8(v + 0.5)
+}
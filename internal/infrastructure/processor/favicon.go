@@ -0,0 +1,125 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+)
+
+const webManifestName = "site.webmanifest"
+
+var (
+	// размеры внутри favicon.ico
+	icoSizes = []int{16, 32, 48}
+
+	// стандартный набор png для браузеров и манифеста веб-приложения
+	faviconPNGs = []struct {
+		name     string
+		size     int
+		manifest bool // попадает в site.webmanifest
+		opaque   bool // iOS заливает прозрачность черным - кладем на фон
+	}{
+		{name: "favicon-16x16.png", size: 16},
+		{name: "favicon-32x32.png", size: 32},
+		{name: "apple-touch-icon.png", size: 180, opaque: true},
+		{name: "android-chrome-192x192.png", size: 192, manifest: true},
+		{name: "android-chrome-512x512.png", size: 512, manifest: true},
+	}
+)
+
+type webManifestIcon struct {
+	Src   string `json:"src"`
+	Sizes string `json:"sizes"`
+	Type  string `json:"type"`
+}
+
+// Favicon собирает favicon.ico с несколькими размерами и набор png с site.webmanifest.
+func (p *ImageProcessor) Favicon(ctx context.Context, data []byte) ([]byte, []dto.File, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ImageProcessor - Favicon - decodeImage: %w", err)
+	}
+
+	// 1. ico из png-кадров
+	entries := make([][]byte, 0, len(icoSizes))
+	for _, size := range icoSizes {
+		b, err := p.encodeImage(squareIcon(img, size), "image/png")
+		if err != nil {
+			return nil, nil, fmt.Errorf("ImageProcessor - Favicon - encodeImage: %w", err)
+		}
+		entries = append(entries, b)
+	}
+	ico := encodeICO(icoSizes, entries)
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("ImageProcessor - Favicon: %w", err)
+	}
+
+	// 2. отдельные png
+	files := make([]dto.File, 0, len(faviconPNGs)+1)
+	var icons []webManifestIcon
+	for _, f := range faviconPNGs {
+		var icon image.Image = squareIcon(img, f.size)
+		if f.opaque {
+			icon = flatten(icon, p.background)
+		}
+
+		b, err := p.encodeImage(icon, "image/png")
+		if err != nil {
+			return nil, nil, fmt.Errorf("ImageProcessor - Favicon - encodeImage: %w", err)
+		}
+		files = append(files, dto.File{Name: f.name, Data: b, ContentType: "image/png"})
+
+		if f.manifest {
+			icons = append(icons, webManifestIcon{
+				Src:   f.name,
+				Sizes: fmt.Sprintf("%dx%d", f.size, f.size),
+				Type:  "image/png",
+			})
+		}
+	}
+
+	// 3. манифест со ссылками на иконки
+	manifest, err := json.Marshal(struct {
+		Icons []webManifestIcon `json:"icons"`
+	}{icons})
+	if err != nil {
+		return nil, nil, fmt.Errorf("ImageProcessor - Favicon - json.Marshal: %w", err)
+	}
+	files = append(files, dto.File{Name: webManifestName, Data: manifest, ContentType: "application/manifest+json"})
+
+	return ico, files, nil
+}
+
+// encodeICO собирает ico из png-кадров: заголовок, каталог, данные.
+func encodeICO(sizes []int, entries [][]byte) []byte {
+	var buf bytes.Buffer
+
+	// ICONDIR: reserved, type = 1 (icon), count
+	_ = binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, uint16(len(entries))})
+
+	offset := 6 + 16*len(entries)
+	for i, e := range entries {
+		side := byte(sizes[i])
+		if sizes[i] >= 256 {
+			side = 0 // 0 означает 256
+		}
+
+		// ICONDIRENTRY: width, height, colors, reserved, planes, bpp, size, offset
+		buf.Write([]byte{side, side, 0, 0})
+		_ = binary.Write(&buf, binary.LittleEndian, [2]uint16{1, 32})
+		_ = binary.Write(&buf, binary.LittleEndian, [2]uint32{uint32(len(e)), uint32(offset)})
+		offset += len(e)
+	}
+
+	for _, e := range entries {
+		buf.Write(e)
+	}
+
+	return buf.Bytes()
+}
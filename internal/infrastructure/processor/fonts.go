@@ -0,0 +1,58 @@
+package processor
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+const (
+	FontRegular = "regular"
+	FontBold    = "bold"
+	FontItalic  = "italic"
+	FontMono    = "mono"
+)
+
+// встроенные шрифты разбираются один раз, при первом использовании
+var fonts = map[string]func() (*opentype.Font, error){
+	FontRegular: parseFont(goregular.TTF),
+	FontBold:    parseFont(gobold.TTF),
+	FontItalic:  parseFont(goitalic.TTF),
+	FontMono:    parseFont(gomono.TTF),
+}
+
+func parseFont(ttf []byte) func() (*opentype.Font, error) {
+	return sync.OnceValues(func() (*opentype.Font, error) {
+		return opentype.Parse(ttf)
+	})
+}
+
+// newFace создает начертание шрифта name кеглем size в пикселях. Неизвестный шрифт - regular.
+func newFace(name string, size float64) (font.Face, error) {
+	load, ok := fonts[name]
+	if !ok {
+		load = fonts[FontRegular]
+	}
+
+	f, err := load()
+	if err != nil {
+		return nil, fmt.Errorf("opentype.Parse: %w", err)
+	}
+
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opentype.NewFace: %w", err)
+	}
+
+	return face, nil
+}
@@ -0,0 +1,632 @@
+package processor
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	// предел размера встроенного профиля
+	maxICCSize = 4 << 20
+
+	// число узлов, которыми аппроксимируются кривые профиля
+	iccCurveSize = 4096
+
+	// допуск, при котором колоранты профиля считаются равными sRGB
+	srgbColorantTolerance = 0.002
+)
+
+// число каналов устройства в цветовом пространстве профиля
+var iccChannels = map[string]int{
+	"RGB ": 3,
+	"CMYK": 4,
+	"GRAY": 1,
+}
+
+var (
+	errICCTruncated   = errors.New("icc profile is truncated")
+	errICCUnsupported = errors.New("icc profile is not supported")
+
+	// белая точка PCS (D50)
+	d50White = [3]float64{0.9642, 1.0, 0.8249}
+
+	// колоранты sRGB, адаптированные к D50: столбцы - R, G, B
+	srgbD50 = [3][3]float64{
+		{0.4360747, 0.3850649, 0.1430804},
+		{0.2225045, 0.7168786, 0.0606169},
+		{0.0139322, 0.0971045, 0.7141733},
+	}
+
+	xyzToLinearSRGB = invert3(srgbD50)
+
+	// линейное значение -> 8-битное sRGB
+	srgbEncodeLUT = func() (lut [iccCurveSize]uint8) {
+		for i := range lut {
+			v := float64(i) / (iccCurveSize - 1)
+			if v <= 0.0031308 {
+				v *= 12.92
+			} else {
+				v = 1.055*math.Pow(v, 1/2.4) - 0.055
+			}
+			lut[i] = clamp8(v * 255)
+		}
+		return lut
+	}()
+)
+
+// iccCurve - кривая тонального отклика, табулированная на [0, 1].
+type iccCurve [iccCurveSize]float64
+
+func (c *iccCurve) at(v float64) float64 {
+	x := v * (iccCurveSize - 1)
+	if x <= 0 {
+		return c[0]
+	}
+	i := int(x)
+	if i >= iccCurveSize-1 {
+		return c[iccCurveSize-1]
+	}
+	f := x - float64(i)
+
+	return c[i] + (c[i+1]-c[i])*f
+}
+
+func curveFromFunc(f func(x float64) float64) *iccCurve {
+	c := new(iccCurve)
+	for i := range c {
+		c[i] = f(float64(i) / (iccCurveSize - 1))
+	}
+	return c
+}
+
+// curveFromTable строит кривую по равномерной таблице значений в [0, 1].
+func curveFromTable(tbl []float64) *iccCurve {
+	n := len(tbl)
+
+	return curveFromFunc(func(x float64) float64 {
+		pos := x * float64(n-1)
+		i := int(pos)
+		if i >= n-1 {
+			return tbl[n-1]
+		}
+		return tbl[i] + (tbl[i+1]-tbl[i])*(pos-float64(i))
+	})
+}
+
+// iccLUT - многомерная таблица преобразования (mft1/mft2) устройство -> PCS.
+type iccLUT struct {
+	in    []*iccCurve
+	out   []*iccCurve
+	grid  int
+	clut  []float64
+	outCh int
+
+	// кодирование Lab: 8-битное (mft1) или устаревшее 16-битное (mft2)
+	lab8 bool
+}
+
+// eval переводит значения каналов устройства в [0, 1] в закодированные значения PCS.
+// При несовпадении числа каналов res не меняется.
+func (l *iccLUT) eval(in []float64, res []float64) {
+	n := len(l.in)
+	if n > len(in) || n > 4 || l.outCh > len(res) || len(l.out) < l.outCh || l.grid < 2 {
+		return
+	}
+
+	var base [4]int
+	var frac [4]float64
+	for d := 0; d < n; d++ {
+		pos := l.in[d].at(in[d]) * float64(l.grid-1)
+		pos = math.Min(math.Max(pos, 0), float64(l.grid-1))
+		base[d] = min(int(pos), l.grid-2)
+		frac[d] = pos - float64(base[d])
+	}
+
+	for o := 0; o < l.outCh; o++ {
+		res[o] = 0
+	}
+
+	// мультилинейная интерполяция по 2^n вершинам ячейки;
+	// первый входной канал меняется медленнее всех
+	for corner := 0; corner < 1<<n; corner++ {
+		w := 1.0
+		idx := 0
+		for d := 0; d < n; d++ {
+			bit := corner >> (n - 1 - d) & 1
+			if bit == 1 {
+				w *= frac[d]
+			} else {
+				w *= 1 - frac[d]
+			}
+			idx = idx*l.grid + base[d] + bit
+		}
+		if w == 0 {
+			continue
+		}
+		idx *= l.outCh
+		if idx+l.outCh > len(l.clut) {
+			continue
+		}
+		for o := 0; o < l.outCh; o++ {
+			res[o] += w * l.clut[idx+o]
+		}
+	}
+
+	for o := 0; o < l.outCh; o++ {
+		res[o] = l.out[o].at(math.Min(math.Max(res[o], 0), 1))
+	}
+}
+
+// iccProfile - часть ICC-профиля, нужная для перевода изображения в sRGB.
+type iccProfile struct {
+	colorSpace string
+	labPCS     bool
+
+	// матричный RGB-профиль: кривые каналов и колоранты (линейный RGB -> XYZ D50)
+	trc    [3]*iccCurve
+	matrix [3][3]float64
+
+	// серый профиль
+	grayTRC *iccCurve
+
+	// табличный профиль (в первую очередь CMYK)
+	lut *iccLUT
+}
+
+// parseICC разбирает профиль. Возвращает nil для sRGB и профилей,
+// которые не нужно или нельзя применить.
+func parseICC(data []byte) (*iccProfile, error) {
+	if len(data) < 132 {
+		return nil, errICCTruncated
+	}
+
+	p := &iccProfile{
+		colorSpace: string(data[16:20]),
+		labPCS:     string(data[20:24]) == "Lab ",
+	}
+
+	tags := make(map[string][]byte)
+	count := int(binary.BigEndian.Uint32(data[128:132]))
+	if count > (len(data)-132)/12 {
+		return nil, errICCTruncated
+	}
+	for i := 0; i < count; i++ {
+		entry := data[132+i*12:]
+		sig := string(entry[:4])
+		offset := int(binary.BigEndian.Uint32(entry[4:8]))
+		size := int(binary.BigEndian.Uint32(entry[8:12]))
+		if offset < 0 || size < 8 || offset > len(data) || size > len(data)-offset {
+			return nil, errICCTruncated
+		}
+		tags[sig] = data[offset : offset+size]
+	}
+
+	switch p.colorSpace {
+	case "RGB ":
+		if err := p.parseMatrixTRC(tags); err == nil {
+			if p.isSRGB() {
+				return nil, nil
+			}
+			return p, nil
+		}
+	case "GRAY":
+		if tag, ok := tags["kTRC"]; ok {
+			c, err := parseCurve(tag)
+			if err != nil {
+				return nil, err
+			}
+			p.grayTRC = c
+			return p, nil
+		}
+	}
+
+	// табличное преобразование: perceptual, иначе colorimetric
+	for _, sig := range []string{"A2B0", "A2B1"} {
+		if tag, ok := tags[sig]; ok {
+			lut, err := parseLUT(tag)
+			if err != nil {
+				return nil, err
+			}
+			// таблица должна принимать столько каналов, сколько их в пространстве профиля
+			if len(lut.in) != iccChannels[p.colorSpace] {
+				return nil, errICCUnsupported
+			}
+			p.lut = lut
+			return p, nil
+		}
+	}
+
+	return nil, errICCUnsupported
+}
+
+func (p *iccProfile) parseMatrixTRC(tags map[string][]byte) error {
+	for ch, name := range []string{"r", "g", "b"} {
+		xyz, ok := tags[name+"XYZ"]
+		if !ok || len(xyz) < 20 {
+			return errICCUnsupported
+		}
+		for row := 0; row < 3; row++ {
+			p.matrix[row][ch] = s15Fixed16(xyz[8+row*4:])
+		}
+
+		trc, ok := tags[name+"TRC"]
+		if !ok {
+			return errICCUnsupported
+		}
+		c, err := parseCurve(trc)
+		if err != nil {
+			return err
+		}
+		p.trc[ch] = c
+	}
+
+	return nil
+}
+
+// isSRGB - колоранты совпадают с sRGB, поэтому преобразование можно пропустить.
+func (p *iccProfile) isSRGB() bool {
+	for row := range p.matrix {
+		for col := range p.matrix[row] {
+			if math.Abs(p.matrix[row][col]-srgbD50[row][col]) > srgbColorantTolerance {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// rgb переводит цвет устройства (каналы в [0, 1]) в 8-битный sRGB.
+func (p *iccProfile) rgb(in []float64) (uint8, uint8, uint8) {
+	switch {
+	case p.lut != nil:
+		var pcs [3]float64
+		p.lut.eval(in, pcs[:])
+		return xyzToSRGB(p.pcsToXYZ(pcs))
+	case p.grayTRC != nil:
+		v := srgbEncode(p.grayTRC.at(in[0]))
+		return v, v, v
+	default:
+		var lin, xyz [3]float64
+		for ch := range lin {
+			lin[ch] = p.trc[ch].at(in[ch])
+		}
+		for row := range xyz {
+			xyz[row] = p.matrix[row][0]*lin[0] + p.matrix[row][1]*lin[1] + p.matrix[row][2]*lin[2]
+		}
+		return xyzToSRGB(xyz)
+	}
+}
+
+// pcsToXYZ раскодирует значения PCS из таблицы в XYZ (D50).
+func (p *iccProfile) pcsToXYZ(v [3]float64) [3]float64 {
+	if !p.labPCS {
+		// u1Fixed15: 0xFFFF соответствует 1 + 32767/32768
+		return [3]float64{v[0] * 65535 / 32768, v[1] * 65535 / 32768, v[2] * 65535 / 32768}
+	}
+
+	scale := 65535.0 / 65280
+	if p.lut.lab8 {
+		scale = 1
+	}
+	l := v[0] * scale * 100
+	a := v[1]*scale*255 - 128
+	b := v[2]*scale*255 - 128
+
+	return labToXYZ(l, a, b)
+}
+
+func labToXYZ(l, a, b float64) [3]float64 {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+
+	inv := func(t float64) float64 {
+		if t > 6.0/29 {
+			return t * t * t
+		}
+		return 3 * (6.0 / 29) * (6.0 / 29) * (t - 4.0/29)
+	}
+
+	return [3]float64{d50White[0] * inv(fx), d50White[1] * inv(fy), d50White[2] * inv(fz)}
+}
+
+func xyzToSRGB(xyz [3]float64) (uint8, uint8, uint8) {
+	var out [3]uint8
+	for row := range out {
+		m := xyzToLinearSRGB[row]
+		out[row] = srgbEncode(m[0]*xyz[0] + m[1]*xyz[1] + m[2]*xyz[2])
+	}
+	return out[0], out[1], out[2]
+}
+
+func srgbEncode(v float64) uint8 {
+	v = math.Min(math.Max(v, 0), 1)
+	return srgbEncodeLUT[int(v*(iccCurveSize-1)+0.5)]
+}
+
+// parseCurve разбирает теги curv и para.
+func parseCurve(tag []byte) (*iccCurve, error) {
+	if len(tag) < 12 {
+		return nil, errICCTruncated
+	}
+
+	switch string(tag[:4]) {
+	case "curv":
+		n := int(binary.BigEndian.Uint32(tag[8:12]))
+		switch {
+		case n == 0:
+			return curveFromFunc(func(x float64) float64 { return x }), nil
+		case n == 1:
+			if len(tag) < 14 {
+				return nil, errICCTruncated
+			}
+			gamma := float64(binary.BigEndian.Uint16(tag[12:14])) / 256
+			return curveFromFunc(func(x float64) float64 { return math.Pow(x, gamma) }), nil
+		}
+		if n > (len(tag)-12)/2 {
+			return nil, errICCTruncated
+		}
+		return curveFromTable(readU16Table(tag[12:], n)), nil
+	case "para":
+		return parseParametricCurve(tag)
+	}
+
+	return nil, errICCUnsupported
+}
+
+func parseParametricCurve(tag []byte) (*iccCurve, error) {
+	fn := int(binary.BigEndian.Uint16(tag[8:10]))
+	counts := []int{1, 3, 4, 5, 7}
+	if fn >= len(counts) {
+		return nil, errICCUnsupported
+	}
+	if len(tag) < 12+counts[fn]*4 {
+		return nil, errICCTruncated
+	}
+
+	var prm [7]float64
+	for i := 0; i < counts[fn]; i++ {
+		prm[i] = s15Fixed16(tag[12+i*4:])
+	}
+	g, a, b, c, d, e, f := prm[0], prm[1], prm[2], prm[3], prm[4], prm[5], prm[6]
+
+	pow := func(x float64) float64 {
+		if x <= 0 {
+			return 0
+		}
+		return math.Pow(x, g)
+	}
+
+	return curveFromFunc(func(x float64) float64 {
+		switch fn {
+		case 0:
+			return pow(x)
+		case 1:
+			if x >= -b/a {
+				return pow(a*x + b)
+			}
+			return 0
+		case 2:
+			if x >= -b/a {
+				return pow(a*x+b) + c
+			}
+			return c
+		case 3:
+			if x >= d {
+				return pow(a*x + b)
+			}
+			return c * x
+		default:
+			if x >= d {
+				return pow(a*x+b) + e
+			}
+			return c*x + f
+		}
+	}), nil
+}
+
+// parseLUT разбирает теги lut8Type (mft1) и lut16Type (mft2).
+func parseLUT(tag []byte) (*iccLUT, error) {
+	if len(tag) < 48 {
+		return nil, errICCTruncated
+	}
+
+	kind := string(tag[:4])
+	if kind != "mft1" && kind != "mft2" {
+		return nil, errICCUnsupported
+	}
+
+	inCh, outCh, grid := int(tag[8]), int(tag[9]), int(tag[10])
+	if inCh < 1 || inCh > 4 || outCh != 3 || grid < 2 {
+		return nil, errICCUnsupported
+	}
+
+	l := &iccLUT{grid: grid, outCh: outCh, lab8: kind == "mft1"}
+
+	// размер элемента и число записей во входных/выходных таблицах
+	size, inEntries, outEntries, pos := 1, 256, 256, 48
+	if kind == "mft2" {
+		if len(tag) < 52 {
+			return nil, errICCTruncated
+		}
+		size = 2
+		inEntries = int(binary.BigEndian.Uint16(tag[48:50]))
+		outEntries = int(binary.BigEndian.Uint16(tag[50:52]))
+		pos = 52
+		if inEntries < 2 || outEntries < 2 {
+			return nil, errICCUnsupported
+		}
+	}
+
+	clutLen := outCh
+	for i := 0; i < inCh; i++ {
+		clutLen *= grid
+	}
+
+	need := pos + (inCh*inEntries+clutLen+outCh*outEntries)*size
+	if len(tag) < need {
+		return nil, errICCTruncated
+	}
+
+	read := func(n int) []float64 {
+		var tbl []float64
+		if size == 2 {
+			tbl = readU16Table(tag[pos:], n)
+		} else {
+			tbl = make([]float64, n)
+			for i := range tbl {
+				tbl[i] = float64(tag[pos+i]) / 255
+			}
+		}
+		pos += n * size
+		return tbl
+	}
+
+	for i := 0; i < inCh; i++ {
+		l.in = append(l.in, curveFromTable(read(inEntries)))
+	}
+	l.clut = read(clutLen)
+	for i := 0; i < outCh; i++ {
+		l.out = append(l.out, curveFromTable(read(outEntries)))
+	}
+
+	return l, nil
+}
+
+func readU16Table(b []byte, n int) []float64 {
+	tbl := make([]float64, n)
+	for i := range tbl {
+		tbl[i] = float64(binary.BigEndian.Uint16(b[i*2:])) / 65535
+	}
+	return tbl
+}
+
+func s15Fixed16(b []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+}
+
+// extractICC достает встроенный профиль из JPEG (APP2) или PNG (iCCP).
+func extractICC(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return jpegICC(data)
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return pngICC(data)
+	}
+
+	return nil
+}
+
+func jpegICC(data []byte) []byte {
+	const iccMarker = "ICC_PROFILE\x00"
+
+	chunks := make(map[int][]byte)
+	total := 0
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			break
+		}
+		marker := data[pos+1]
+		// начало данных скана - дальше метаданных нет
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			break
+		}
+		segment := data[pos+4 : pos+2+length]
+
+		if marker == 0xE2 && len(segment) > len(iccMarker)+2 && string(segment[:len(iccMarker)]) == iccMarker {
+			seq := int(segment[len(iccMarker)])
+			chunks[seq] = segment[len(iccMarker)+2:]
+			total += len(chunks[seq])
+		}
+
+		pos += 2 + length
+	}
+
+	if len(chunks) == 0 || total > maxICCSize {
+		return nil
+	}
+
+	// части нумеруются с единицы
+	profile := make([]byte, 0, total)
+	for seq := 1; seq <= len(chunks); seq++ {
+		chunk, ok := chunks[seq]
+		if !ok {
+			return nil
+		}
+		profile = append(profile, chunk...)
+	}
+
+	return profile
+}
+
+func pngICC(data []byte) []byte {
+	pos := 8
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		kind := string(data[pos+4 : pos+8])
+		if length < 0 || pos+12+length > len(data) {
+			return nil
+		}
+		chunk := data[pos+8 : pos+8+length]
+
+		switch kind {
+		case "iCCP":
+			// имя профиля, 0, метод сжатия, zlib-поток
+			name := bytes.IndexByte(chunk, 0)
+			if name < 0 || name+2 > len(chunk) {
+				return nil
+			}
+			r, err := zlib.NewReader(bytes.NewReader(chunk[name+2:]))
+			if err != nil {
+				return nil
+			}
+			defer r.Close()
+
+			profile, err := io.ReadAll(io.LimitReader(r, maxICCSize))
+			if err != nil {
+				return nil
+			}
+			return profile
+		case "IDAT":
+			return nil
+		}
+
+		pos += 12 + length
+	}
+
+	return nil
+}
+
+func invert3(m [3][3]float64) [3][3]float64 {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+
+	return [3][3]float64{
+		{
+			(m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det,
+			(m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det,
+			(m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det,
+		},
+		{
+			(m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det,
+			(m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det,
+			(m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det,
+		},
+		{
+			(m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det,
+			(m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det,
+			(m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det,
+		},
+	}
+}
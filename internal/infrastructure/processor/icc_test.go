@@ -0,0 +1,140 @@
+package processor
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// lutProfile собирает ICC-профиль с единственным тегом A2B0 типа mft1 на inCh входов.
+func lutProfile(colorSpace string, inCh int) []byte {
+	const grid = 2
+
+	tag := make([]byte, 48)
+	copy(tag, "mft1")
+	tag[8], tag[9], tag[10] = byte(inCh), 3, grid
+	for i := 0; i < inCh*256; i++ {
+		tag = append(tag, byte(i))
+	}
+	clutLen := 3
+	for i := 0; i < inCh; i++ {
+		clutLen *= grid
+	}
+	tag = append(tag, make([]byte, clutLen)...)
+	for i := 0; i < 3*256; i++ {
+		tag = append(tag, byte(i))
+	}
+
+	header := make([]byte, 132)
+	copy(header[16:20], colorSpace)
+	copy(header[20:24], "XYZ ")
+	binary.BigEndian.PutUint32(header[128:132], 1)
+
+	entry := make([]byte, 12)
+	copy(entry, "A2B0")
+	binary.BigEndian.PutUint32(entry[4:8], uint32(len(header)+len(entry)))
+	binary.BigEndian.PutUint32(entry[8:12], uint32(len(tag)))
+
+	profile := append(append(header, entry...), tag...)
+	binary.BigEndian.PutUint32(profile[0:4], uint32(len(profile)))
+
+	return profile
+}
+
+// pngWithICC кодирует RGB-изображение и вставляет iCCP с профилем сразу после IHDR.
+func pngWithICC(t *testing.T, profile []byte) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(profile)
+	zw.Close()
+
+	data := append([]byte("icc\x00\x00"), z.Bytes()...)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, "iCCP"...)
+	chunk = append(chunk, data...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	// сигнатура (8) + IHDR (4 + 4 + 13 + 4)
+	const ihdrEnd = 33
+	encoded := buf.Bytes()
+	res := append([]byte{}, encoded[:ihdrEnd]...)
+	res = append(res, chunk...)
+	return append(res, encoded[ihdrEnd:]...)
+}
+
+func TestParseICCRejectsLUTChannelMismatch(t *testing.T) {
+	tests := []struct {
+		colorSpace string
+		inCh       int
+		wantLUT    bool
+	}{
+		{colorSpace: "RGB ", inCh: 3, wantLUT: true},
+		{colorSpace: "RGB ", inCh: 4},
+		{colorSpace: "RGB ", inCh: 1},
+		{colorSpace: "CMYK", inCh: 4, wantLUT: true},
+		{colorSpace: "CMYK", inCh: 3},
+		{colorSpace: "GRAY", inCh: 1, wantLUT: true},
+		{colorSpace: "GRAY", inCh: 3},
+		{colorSpace: "Lab ", inCh: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.colorSpace, func(t *testing.T) {
+			p, err := parseICC(lutProfile(tt.colorSpace, tt.inCh))
+			if got := err == nil && p != nil && p.lut != nil; got != tt.wantLUT {
+				t.Fatalf("parseICC(%q, inCh=%d) = %v, %v; want lut %v", tt.colorSpace, tt.inCh, p, err, tt.wantLUT)
+			}
+		})
+	}
+}
+
+// Профиль RGB без колорантов с таблицей на 4 входа раньше ронял декодирование index out of range.
+func TestDecodeImageWithMismatchedLUTProfile(t *testing.T) {
+	data := pngWithICC(t, lutProfile("RGB ", 4))
+
+	if extractICC(data) == nil {
+		t.Fatal("extractICC: profile not found in crafted png")
+	}
+
+	img, err := decodeImage(data)
+	if err != nil {
+		t.Fatalf("decodeImage: %v", err)
+	}
+
+	// профиль отброшен - пиксели без преобразования
+	if got := color.NRGBAModel.Convert(img.At(0, 0)).(color.NRGBA); got != (color.NRGBA{0x80, 0x80, 0x80, 0x80}) {
+		t.Fatalf("pixel = %v, want unconverted", got)
+	}
+}
+
+func TestLUTEvalShortInput(t *testing.T) {
+	p, err := parseICC(lutProfile("CMYK", 4))
+	if err != nil || p == nil || p.lut == nil {
+		t.Fatalf("parseICC: %v, %v", p, err)
+	}
+
+	res := []float64{0.5, 0.5, 0.5}
+	p.lut.eval([]float64{0.1, 0.2, 0.3}, res)
+	if res[0] != 0.5 || res[1] != 0.5 || res[2] != 0.5 {
+		t.Fatalf("eval with short input changed result: %v", res)
+	}
+
+	p.lut.eval([]float64{0.1, 0.2, 0.3, 0.4}, res[:2])
+}
@@ -7,7 +7,9 @@ import (
 	"image"
 	"image/color"
 
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
 	"github.com/disintegration/imaging"
+	"github.com/srwiley/oksvg"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
 	"golang.org/x/image/math/fixed"
@@ -19,10 +21,22 @@ const (
 )
 
 type ImageProcessor struct {
+	// фон, на который накладываются полупрозрачные изображения при сохранении в JPEG
+	background color.Color
+	// EXIF/XMP по умолчанию для JPEG и PNG, пишутся в WriteMetadata
+	metadata dto.OutputMetadata
 }
 
-func New() *ImageProcessor {
-	return &ImageProcessor{}
+func New(opts ...Option) *ImageProcessor {
+	p := &ImageProcessor{
+		background: color.White,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 func (p *ImageProcessor) Resize(ctx context.Context, contentType string, data []byte, width, height int) ([]byte, error) {
@@ -31,9 +45,17 @@ func (p *ImageProcessor) Resize(ctx context.Context, contentType string, data []
 		return nil, fmt.Errorf("ImageProcessor - Resize - decodeImage: %w", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Resize: %w", err)
+	}
+
 	resized := imaging.Resize(img, width, height, imaging.Lanczos)
 
-	res, err := encodeImage(resized, contentType)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Resize: %w", err)
+	}
+
+	res, err := p.encodeImage(resized, contentType)
 	if err != nil {
 		return nil, fmt.Errorf("ImageProcessor - Resize - encodeImage: %w", err)
 	}
@@ -47,9 +69,17 @@ func (p *ImageProcessor) Thumbnail(ctx context.Context, contentType string, data
 		return nil, fmt.Errorf("ImageProcessor - Thumbnail - decodeImage: %w", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Thumbnail: %w", err)
+	}
+
 	thumb := imaging.Thumbnail(img, thumbWidth, thumbHeight, imaging.Lanczos)
 
-	res, err := encodeImage(thumb, contentType)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Thumbnail: %w", err)
+	}
+
+	res, err := p.encodeImage(thumb, contentType)
 	if err != nil {
 		return nil, fmt.Errorf("ImageProcessor - ResiThumbnailze - encodeImage: %w", err)
 	}
@@ -63,6 +93,10 @@ func (p *ImageProcessor) Watermark(ctx context.Context, contentType string, data
 		return nil, fmt.Errorf("ImageProcessor - Watermark - decodeImage: %w", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Watermark: %w", err)
+	}
+
 	rgba := imaging.Clone(img)
 
 	d := &font.Drawer{
@@ -81,7 +115,11 @@ func (p *ImageProcessor) Watermark(ctx context.Context, contentType string, data
 
 	d.DrawString(text)
 
-	res, err := encodeImage(rgba, contentType)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Watermark: %w", err)
+	}
+
+	res, err := p.encodeImage(rgba, contentType)
 	if err != nil {
 		return nil, fmt.Errorf("ImageProcessor - Watermark - encodeImage: %w", err)
 	}
@@ -89,26 +127,69 @@ func (p *ImageProcessor) Watermark(ctx context.Context, contentType string, data
 	return res, nil
 }
 
+// imageSize читает размер изображения по заголовку, не декодируя пиксели.
+// Для SVG - размер растра, который получит decodeImage.
+func imageSize(data []byte) (int, int, error) {
+	if isSVG(data) {
+		icon, err := oksvg.ReadIconStream(bytes.NewReader(data))
+		if err != nil {
+			return 0, 0, fmt.Errorf("imageSize - oksvg.ReadIconStream: %w", err)
+		}
+		w, h := svgSize(icon.ViewBox.W, icon.ViewBox.H, dto.RasterizeOptions{})
+		return w, h, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("imageSize - image.DecodeConfig: %w", err)
+	}
+
+	return cfg.Width, cfg.Height, nil
+}
+
 func decodeImage(data []byte) (image.Image, error) {
+	// векторные исходники отрисовываем в собственном размере
+	if isSVG(data) {
+		img, err := rasterizeSVG(data, dto.RasterizeOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("ImageProcessor - decodeImage - rasterizeSVG: %w", err)
+		}
+		return img, nil
+	}
+
 	img, err := imaging.Decode(bytes.NewReader(data))
 	if err != nil {
 		return nil, fmt.Errorf("ImageProcessor - decodeImage - imaging.Decode: %w", err)
 	}
 
-	return img, nil
+	// битый или неподдерживаемый профиль не мешает обработке - считаем изображение sRGB
+	var profile *iccProfile
+	if icc := extractICC(data); icc != nil {
+		profile, _ = parseICC(icc)
+	}
+
+	return toSRGB(img, profile), nil
 }
 
-func encodeImage(img image.Image, contentType string) ([]byte, error) {
+func (p *ImageProcessor) encodeImage(img image.Image, contentType string) ([]byte, error) {
 	var buf bytes.Buffer
 	var format imaging.Format
 
 	switch contentType {
 	case "image/jpeg", "image/jpg":
 		format = imaging.JPEG
+		img = flatten(img, p.background)
 	case "image/png":
 		format = imaging.PNG
+	case "image/gif":
+		format = imaging.GIF
+	case "image/bmp":
+		format = imaging.BMP
+	case "image/tiff":
+		format = imaging.TIFF
 	default:
 		format = imaging.JPEG
+		img = flatten(img, p.background)
 	}
 
 	err := imaging.Encode(&buf, img, format)
@@ -0,0 +1,318 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"sort"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+)
+
+const (
+	exifTagImageDescription uint16 = 0x010e
+	exifTagArtist           uint16 = 0x013b
+	exifTagCopyright        uint16 = 0x8298
+	exifTypeASCII           uint16 = 2
+
+	// пространство имен для произвольных полей XMP
+	xmpCustomNamespace = "https://github.com/andreyxaxa/Image-Processor/ns/1.0/"
+	xmpCustomPrefix    = "ipx"
+
+	// максимальный размер данных сегмента JPEG
+	jpegSegmentMax = 0xffff - 2
+)
+
+var (
+	ErrMetadataTooLarge = errors.New("metadata is too large")
+
+	jpegExifHeader = []byte("Exif\x00\x00")
+	jpegXMPHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	pngSignature   = []byte("\x89PNG\r\n\x1a\n")
+	pngXMPKeyword  = []byte("XML:com.adobe.xmp\x00")
+
+	// произвольные ключи становятся именами элементов XMP
+	xmpNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+)
+
+// WriteMetadata записывает поля EXIF/XMP в готовый JPEG или PNG. Поля запроса
+// накладываются на значения по умолчанию, прежние EXIF/XMP заменяются.
+// Остальные форматы возвращаются без изменений.
+func (p *ImageProcessor) WriteMetadata(ctx context.Context, data []byte, override dto.OutputMetadata) ([]byte, error) {
+	res, err := injectMetadata(data, mergeOutputMetadata(p.metadata, override))
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - WriteMetadata - injectMetadata: %w", err)
+	}
+
+	return res, nil
+}
+
+// mergeOutputMetadata накладывает непустые поля override поверх base.
+func mergeOutputMetadata(base, override dto.OutputMetadata) dto.OutputMetadata {
+	res := base
+	if override.Copyright != "" {
+		res.Copyright = override.Copyright
+	}
+	if override.Artist != "" {
+		res.Artist = override.Artist
+	}
+	if override.Description != "" {
+		res.Description = override.Description
+	}
+	if override.SourceURL != "" {
+		res.SourceURL = override.SourceURL
+	}
+
+	if len(override.Custom) > 0 {
+		res.Custom = make(map[string]string, len(base.Custom)+len(override.Custom))
+		for k, v := range base.Custom {
+			res.Custom[k] = v
+		}
+		for k, v := range override.Custom {
+			res.Custom[k] = v
+		}
+	}
+
+	return res
+}
+
+// injectMetadata определяет формат по сигнатуре и вставляет EXIF и XMP.
+func injectMetadata(data []byte, m dto.OutputMetadata) ([]byte, error) {
+	if m.Copyright == "" && m.Artist == "" && m.Description == "" && m.SourceURL == "" && len(m.Custom) == 0 {
+		return data, nil
+	}
+
+	exif := buildExif(m)
+	xmp := buildXMP(m)
+
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xd8}):
+		return spliceJPEG(data, exif, xmp)
+	case bytes.HasPrefix(data, pngSignature):
+		return splicePNG(data, exif, xmp)
+	}
+
+	return data, nil
+}
+
+// buildExif собирает TIFF-структуру с одним IFD из текстовых тегов. Пустые поля пропускаются.
+func buildExif(m dto.OutputMetadata) []byte {
+	type entry struct {
+		tag   uint16
+		value string
+	}
+
+	// теги IFD идут по возрастанию
+	var entries []entry
+	for _, e := range []entry{
+		{exifTagImageDescription, m.Description},
+		{exifTagArtist, m.Artist},
+		{exifTagCopyright, m.Copyright},
+	} {
+		if e.value != "" {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	be := binary.BigEndian
+	ifdSize := 2 + 12*len(entries) + 4
+
+	var ifd, values []byte
+	ifd = be.AppendUint16(ifd, uint16(len(entries)))
+	for _, e := range entries {
+		value := append([]byte(e.value), 0)
+
+		ifd = be.AppendUint16(ifd, e.tag)
+		ifd = be.AppendUint16(ifd, exifTypeASCII)
+		ifd = be.AppendUint32(ifd, uint32(len(value)))
+
+		// до 4 байт хранятся прямо в записи
+		if len(value) <= 4 {
+			var inline [4]byte
+			copy(inline[:], value)
+			ifd = append(ifd, inline[:]...)
+			continue
+		}
+
+		ifd = be.AppendUint32(ifd, uint32(8+ifdSize+len(values)))
+		values = append(values, value...)
+		if len(values)%2 == 1 {
+			values = append(values, 0)
+		}
+	}
+	ifd = be.AppendUint32(ifd, 0)
+
+	res := []byte{'M', 'M', 0, 0x2a}
+	res = be.AppendUint32(res, 8)
+	res = append(res, ifd...)
+	return append(res, values...)
+}
+
+// buildXMP собирает пакет XMP: dc:rights, dc:creator, dc:description, dc:source и произвольные поля.
+func buildXMP(m dto.OutputMetadata) []byte {
+	var b bytes.Buffer
+
+	text := func(s string) {
+		_ = xml.EscapeText(&b, []byte(s))
+	}
+	alt := func(name, value string) {
+		if value == "" {
+			return
+		}
+		fmt.Fprintf(&b, "   <%s><rdf:Alt><rdf:li xml:lang=\"x-default\">", name)
+		text(value)
+		fmt.Fprintf(&b, "</rdf:li></rdf:Alt></%s>\n", name)
+	}
+
+	b.WriteString("<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	b.WriteString(" <rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n")
+	fmt.Fprintf(&b, "  <rdf:Description rdf:about=\"\" xmlns:dc=\"http://purl.org/dc/elements/1.1/\" xmlns:%s=\"%s\">\n",
+		xmpCustomPrefix, xmpCustomNamespace)
+
+	alt("dc:rights", m.Copyright)
+	if m.Artist != "" {
+		b.WriteString("   <dc:creator><rdf:Seq><rdf:li>")
+		text(m.Artist)
+		b.WriteString("</rdf:li></rdf:Seq></dc:creator>\n")
+	}
+	alt("dc:description", m.Description)
+	if m.SourceURL != "" {
+		b.WriteString("   <dc:source>")
+		text(m.SourceURL)
+		b.WriteString("</dc:source>\n")
+	}
+
+	keys := make([]string, 0, len(m.Custom))
+	for k := range m.Custom {
+		if xmpNameRe.MatchString(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "   <%s:%s>", xmpCustomPrefix, k)
+		text(m.Custom[k])
+		fmt.Fprintf(&b, "</%s:%s>\n", xmpCustomPrefix, k)
+	}
+
+	b.WriteString("  </rdf:Description>\n")
+	b.WriteString(" </rdf:RDF>\n")
+	b.WriteString("</x:xmpmeta>\n")
+	b.WriteString("<?xpacket end=\"w\"?>")
+
+	return b.Bytes()
+}
+
+// spliceJPEG удаляет прежние APP1 с EXIF/XMP и вставляет новые после SOI и APP0.
+func spliceJPEG(data, exif, xmp []byte) ([]byte, error) {
+	var segments [][]byte
+	if exif != nil {
+		segments = append(segments, append(append([]byte{}, jpegExifHeader...), exif...))
+	}
+	segments = append(segments, append(append([]byte{}, jpegXMPHeader...), xmp...))
+
+	res := make([]byte, 0, len(data)+len(exif)+len(xmp)+64)
+	res = append(res, data[:2]...)
+
+	inserted := false
+	insert := func() error {
+		for _, s := range segments {
+			if len(s) > jpegSegmentMax {
+				return ErrMetadataTooLarge
+			}
+			res = append(res, 0xff, 0xe1)
+			res = binary.BigEndian.AppendUint16(res, uint16(len(s)+2))
+			res = append(res, s...)
+		}
+		inserted = true
+		return nil
+	}
+
+	// сегменты заголовка до начала скана
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xff {
+		marker := data[pos+1]
+		if marker == 0xda || marker == 0xd9 {
+			break
+		}
+
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end > len(data) {
+			break
+		}
+		segment := data[pos:end]
+		payload := segment[4:]
+		pos = end
+
+		if !inserted && marker != 0xe0 {
+			if err := insert(); err != nil {
+				return nil, err
+			}
+		}
+
+		if marker == 0xe1 && (bytes.HasPrefix(payload, jpegExifHeader) || bytes.HasPrefix(payload, jpegXMPHeader)) {
+			continue
+		}
+		res = append(res, segment...)
+	}
+
+	if !inserted {
+		if err := insert(); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(res, data[pos:]...), nil
+}
+
+// splicePNG удаляет прежние eXIf и iTXt с XMP и вставляет новые сразу после IHDR.
+func splicePNG(data, exif, xmp []byte) ([]byte, error) {
+	res := make([]byte, 0, len(data)+len(exif)+len(xmp)+64)
+	res = append(res, pngSignature...)
+
+	appendChunk := func(typ string, body []byte) {
+		res = binary.BigEndian.AppendUint32(res, uint32(len(body)))
+		start := len(res)
+		res = append(res, typ...)
+		res = append(res, body...)
+		res = binary.BigEndian.AppendUint32(res, crc32.ChecksumIEEE(res[start:]))
+	}
+
+	pos := len(pngSignature)
+	for pos+12 <= len(data) {
+		n := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + n
+		if end > len(data) {
+			return nil, fmt.Errorf("splicePNG: truncated chunk at %d", pos)
+		}
+		typ := string(data[pos+4 : pos+8])
+		body := data[pos+8 : pos+8+n]
+		chunk := data[pos:end]
+		pos = end
+
+		if typ == "eXIf" || (typ == "iTXt" && bytes.HasPrefix(body, pngXMPKeyword)) {
+			continue
+		}
+		res = append(res, chunk...)
+
+		if typ == "IHDR" {
+			if exif != nil {
+				appendChunk("eXIf", exif)
+			}
+			// ключевое слово, без сжатия, без языка и перевода
+			itxt := append(append([]byte{}, pngXMPKeyword...), 0, 0, 0, 0)
+			appendChunk("iTXt", append(itxt, xmp...))
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,23 @@
+package processor
+
+import (
+	"image/color"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+)
+
+type Option func(*ImageProcessor)
+
+// Background задает фон для форматов без прозрачности.
+func Background(c color.Color) Option {
+	return func(p *ImageProcessor) {
+		p.background = c
+	}
+}
+
+// Metadata задает поля EXIF/XMP по умолчанию для WriteMetadata.
+func Metadata(m dto.OutputMetadata) Option {
+	return func(p *ImageProcessor) {
+		p.metadata = m
+	}
+}
@@ -0,0 +1,124 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// резкость считается на уменьшенной копии, чтобы не зависеть от разрешения
+	qualitySide = 1024
+
+	blurThreshold = 100.0
+
+	// пиксели ярче/темнее считаются выбитыми
+	shadowLevel    = 4
+	highlightLevel = 251
+
+	clippedThreshold   = 0.05
+	darkThreshold      = 50.0
+	brightThreshold    = 205.0
+	minQualityShortest = 400
+)
+
+// AssessQuality оценивает резкость (дисперсия лапласиана), экспозицию и разрешение.
+func (p *ImageProcessor) AssessQuality(ctx context.Context, data []byte) (entity.Quality, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return entity.Quality{}, fmt.Errorf("ImageProcessor - AssessQuality - decodeImage: %w", err)
+	}
+
+	b := img.Bounds()
+	q := entity.Quality{
+		Width:  b.Dx(),
+		Height: b.Dy(),
+	}
+
+	var small *image.NRGBA
+	if max(b.Dx(), b.Dy()) > qualitySide {
+		small = imaging.Fit(img, qualitySide, qualitySide, imaging.Box)
+	} else {
+		small = imaging.Clone(img)
+	}
+
+	// 1. экспозиция
+	plane := lumaPlane(small)
+	var sum, sumSq float64
+	var shadows, highlights int
+	for _, v := range plane {
+		sum += v
+		sumSq += v * v
+		if v <= shadowLevel {
+			shadows++
+		}
+		if v >= highlightLevel {
+			highlights++
+		}
+	}
+
+	n := float64(len(plane))
+	q.Brightness = sum / n
+	q.Contrast = math.Sqrt(math.Max(sumSq/n-q.Brightness*q.Brightness, 0))
+	q.ClippedShadows = float64(shadows) / n
+	q.ClippedHighlights = float64(highlights) / n
+
+	if err := ctx.Err(); err != nil {
+		return entity.Quality{}, fmt.Errorf("ImageProcessor - AssessQuality: %w", err)
+	}
+
+	// 2. резкость
+	q.Sharpness = laplacianVariance(plane, small.Bounds().Dx(), small.Bounds().Dy())
+
+	// 3. вердикт
+	if q.Sharpness < blurThreshold {
+		q.Issues = append(q.Issues, entity.IssueBlurry)
+	}
+	if q.ClippedHighlights > clippedThreshold || q.Brightness > brightThreshold {
+		q.Issues = append(q.Issues, entity.IssueOverexposed)
+	}
+	if q.ClippedShadows > clippedThreshold || q.Brightness < darkThreshold {
+		q.Issues = append(q.Issues, entity.IssueUnderexposed)
+	}
+	if min(q.Width, q.Height) < minQualityShortest {
+		q.Issues = append(q.Issues, entity.IssueLowResolution)
+	}
+
+	switch len(q.Issues) {
+	case 0:
+		q.Grade = entity.QualityGood
+	case 1:
+		q.Grade = entity.QualityFair
+	default:
+		q.Grade = entity.QualityPoor
+	}
+
+	return q, nil
+}
+
+// laplacianVariance - дисперсия отклика ядра Лапласа 3x3 по внутренним пикселям.
+func laplacianVariance(plane []float64, w, h int) float64 {
+	if w < 3 || h < 3 {
+		return 0
+	}
+
+	var sum, sumSq float64
+	for y := 1; y < h-1; y++ {
+		row := plane[y*w:]
+		up := plane[(y-1)*w:]
+		down := plane[(y+1)*w:]
+		for x := 1; x < w-1; x++ {
+			v := up[x] + down[x] + row[x-1] + row[x+1] - 4*row[x]
+			sum += v
+			sumSq += v * v
+		}
+	}
+
+	n := float64((w - 2) * (h - 2))
+	mean := sum / n
+	return sumSq/n - mean*mean
+}
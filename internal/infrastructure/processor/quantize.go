@@ -0,0 +1,248 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+)
+
+const (
+	algorithmKMeans = "kmeans"
+
+	// максимум пикселей, по которым строится палитра
+	maxPaletteSamples = 1 << 18
+	kmeansIterations  = 8
+)
+
+func (p *ImageProcessor) Quantize(ctx context.Context, contentType string, data []byte, opts dto.QuantizeOptions) ([]byte, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Quantize - decodeImage: %w", err)
+	}
+
+	src := imaging.Clone(img)
+	samples := samplePixels(src)
+
+	palette := medianCut(samples, opts.Colors)
+	if opts.Algorithm == algorithmKMeans {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("ImageProcessor - Quantize: %w", err)
+		}
+		palette = kmeans(samples, palette, kmeansIterations)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Quantize: %w", err)
+	}
+
+	paletted := image.NewPaletted(src.Bounds(), palette)
+
+	var drawer draw.Drawer = draw.Src
+	if opts.Dither {
+		drawer = draw.FloydSteinberg
+	}
+	drawer.Draw(paletted, src.Bounds(), src, src.Bounds().Min)
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Quantize: %w", err)
+	}
+
+	res, err := p.encodeImage(paletted, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Quantize - encodeImage: %w", err)
+	}
+
+	return res, nil
+}
+
+// samplePixels возвращает пиксели изображения, прореживая большие изображения.
+func samplePixels(img *image.NRGBA) []color.NRGBA {
+	b := img.Bounds()
+	total := b.Dx() * b.Dy()
+
+	step := 1
+	for total/(step*step) > maxPaletteSamples {
+		step++
+	}
+
+	samples := make([]color.NRGBA, 0, total/(step*step)+1)
+	for y := b.Min.Y; y < b.Max.Y; y += step {
+		for x := b.Min.X; x < b.Max.X; x += step {
+			samples = append(samples, img.NRGBAAt(x, y))
+		}
+	}
+
+	return samples
+}
+
+// colorBox - группа пикселей для median-cut.
+type colorBox struct {
+	pixels []color.NRGBA
+}
+
+// widestChannel возвращает канал с наибольшим разбросом значений и сам разброс.
+func (b colorBox) widestChannel() (int, int) {
+	lo := [4]uint8{255, 255, 255, 255}
+	hi := [4]uint8{}
+
+	for _, c := range b.pixels {
+		ch := [4]uint8{c.R, c.G, c.B, c.A}
+		for i, v := range ch {
+			lo[i] = min(lo[i], v)
+			hi[i] = max(hi[i], v)
+		}
+	}
+
+	channel, spread := 0, -1
+	for i := 0; i < 4; i++ {
+		if d := int(hi[i]) - int(lo[i]); d > spread {
+			channel, spread = i, d
+		}
+	}
+
+	return channel, spread
+}
+
+func (b colorBox) average() color.Color {
+	var r, g, bl, a int
+	for _, c := range b.pixels {
+		r += int(c.R)
+		g += int(c.G)
+		bl += int(c.B)
+		a += int(c.A)
+	}
+
+	n := len(b.pixels)
+
+	return color.NRGBA{
+		R: uint8(r / n),
+		G: uint8(g / n),
+		B: uint8(bl / n),
+		A: uint8(a / n),
+	}
+}
+
+func channelValue(c color.NRGBA, channel int) uint8 {
+	switch channel {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	case 2:
+		return c.B
+	default:
+		return c.A
+	}
+}
+
+// medianCut строит палитру из n цветов, каждый раз деля пополам группу с наибольшим разбросом.
+func medianCut(samples []color.NRGBA, n int) color.Palette {
+	if len(samples) == 0 {
+		return color.Palette{color.Black}
+	}
+
+	boxes := []colorBox{{pixels: samples}}
+
+	for len(boxes) < n {
+		// выбираем группу с наибольшим разбросом
+		idx, channel, spread := -1, 0, 0
+		for i, box := range boxes {
+			if len(box.pixels) < 2 {
+				continue
+			}
+			ch, s := box.widestChannel()
+			if s > spread {
+				idx, channel, spread = i, ch, s
+			}
+		}
+
+		// все группы однородны - цветов меньше, чем запрошено
+		if idx == -1 {
+			break
+		}
+
+		pixels := boxes[idx].pixels
+		sort.Slice(pixels, func(i, j int) bool {
+			return channelValue(pixels[i], channel) < channelValue(pixels[j], channel)
+		})
+
+		mid := len(pixels) / 2
+		boxes[idx] = colorBox{pixels: pixels[:mid]}
+		boxes = append(boxes, colorBox{pixels: pixels[mid:]})
+	}
+
+	palette := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		palette = append(palette, box.average())
+	}
+
+	return palette
+}
+
+// kmeans уточняет палитру итерациями k-means, начиная с переданной палитры.
+func kmeans(samples []color.NRGBA, palette color.Palette, iterations int) color.Palette {
+	k := len(palette)
+	centers := make([][4]float64, k)
+	for i, c := range palette {
+		nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+		centers[i] = [4]float64{float64(nc.R), float64(nc.G), float64(nc.B), float64(nc.A)}
+	}
+
+	sums := make([][4]float64, k)
+	counts := make([]int, k)
+
+	for it := 0; it < iterations; it++ {
+		for i := range sums {
+			sums[i] = [4]float64{}
+			counts[i] = 0
+		}
+
+		for _, c := range samples {
+			px := [4]float64{float64(c.R), float64(c.G), float64(c.B), float64(c.A)}
+
+			best, bestDist := 0, -1.0
+			for i, center := range centers {
+				var d float64
+				for ch := range px {
+					diff := px[ch] - center[ch]
+					d += diff * diff
+				}
+				if bestDist < 0 || d < bestDist {
+					best, bestDist = i, d
+				}
+			}
+
+			for ch := range px {
+				sums[best][ch] += px[ch]
+			}
+			counts[best]++
+		}
+
+		for i := range centers {
+			if counts[i] == 0 {
+				continue
+			}
+			for ch := range centers[i] {
+				centers[i][ch] = sums[i][ch] / float64(counts[i])
+			}
+		}
+	}
+
+	result := make(color.Palette, k)
+	for i, c := range centers {
+		result[i] = color.NRGBA{
+			R: uint8(c[0] + 0.5),
+			G: uint8(c[1] + 0.5),
+			B: uint8(c[2] + 0.5),
+			A: uint8(c[3] + 0.5),
+		}
+	}
+
+	return result
+}
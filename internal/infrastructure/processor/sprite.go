@@ -0,0 +1,150 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"sort"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+)
+
+var ErrSpriteTooLarge = errors.New("sprite sheet is too large")
+
+// Sprite упаковывает исходники в один png полками (кадры по убыванию высоты)
+// и возвращает лист и манифест с координатами кадров в json.
+func (p *ImageProcessor) Sprite(ctx context.Context, sources [][]byte, opts dto.SpriteOptions) ([]byte, []byte, error) {
+	// 1. площадь кадров по заголовкам, до декодирования: ни кадр, ни сумма кадров
+	// не должны превышать лимит листа
+	area := 0
+	for _, src := range sources {
+		w, h, err := imageSize(src)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ImageProcessor - Sprite - imageSize: %w", err)
+		}
+		if w*h > maxRasterPixels {
+			return nil, nil, fmt.Errorf("ImageProcessor - Sprite: %w", ErrSpriteTooLarge)
+		}
+
+		if opts.IconSize > 0 {
+			w, h = opts.IconSize, opts.IconSize
+		}
+		area += (w + opts.Padding) * (h + opts.Padding)
+		if area > maxRasterPixels {
+			return nil, nil, fmt.Errorf("ImageProcessor - Sprite: %w", ErrSpriteTooLarge)
+		}
+	}
+
+	// 2. декодирование
+	frames := make([]*image.NRGBA, 0, len(sources))
+	for _, src := range sources {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, fmt.Errorf("ImageProcessor - Sprite: %w", err)
+		}
+
+		img, err := decodeImage(src)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ImageProcessor - Sprite - decodeImage: %w", err)
+		}
+
+		if opts.IconSize > 0 {
+			frames = append(frames, squareIcon(img, opts.IconSize))
+		} else {
+			frames = append(frames, imaging.Clone(img))
+		}
+	}
+
+	// 3. упаковка; полки оставляют пустоты, поэтому площадь листа проверяем еще раз
+	manifest := packShelves(frames, opts)
+	if manifest.Width > maxRasterSide || manifest.Height > maxRasterSide ||
+		manifest.Width*manifest.Height > maxRasterPixels {
+		return nil, nil, fmt.Errorf("ImageProcessor - Sprite: %w", ErrSpriteTooLarge)
+	}
+
+	sheet := image.NewNRGBA(image.Rect(0, 0, manifest.Width, manifest.Height))
+	for i, f := range manifest.Frames {
+		draw.Draw(sheet, image.Rect(f.X, f.Y, f.X+f.Width, f.Y+f.Height), frames[i], image.Point{}, draw.Src)
+	}
+
+	res, err := p.encodeImage(sheet, "image/png")
+	if err != nil {
+		return nil, nil, fmt.Errorf("ImageProcessor - Sprite - encodeImage: %w", err)
+	}
+
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ImageProcessor - Sprite - json.Marshal: %w", err)
+	}
+
+	return res, b, nil
+}
+
+// packShelves раскладывает кадры полками шириной около корня из суммарной площади.
+// Кадры в манифесте идут в порядке исходников.
+func packShelves(frames []*image.NRGBA, opts dto.SpriteOptions) dto.SpriteManifest {
+	manifest := dto.SpriteManifest{Frames: make([]dto.SpriteFrame, len(frames))}
+
+	area, widest := 0, 0
+	for i, f := range frames {
+		w, h := f.Bounds().Dx(), f.Bounds().Dy()
+		area += (w + opts.Padding) * (h + opts.Padding)
+		widest = max(widest, w)
+
+		manifest.Frames[i] = dto.SpriteFrame{Width: w, Height: h}
+		if i < len(opts.IDs) {
+			manifest.Frames[i].ID = opts.IDs[i]
+		}
+	}
+
+	limit := max(widest, int(math.Ceil(math.Sqrt(float64(area)))))
+
+	order := make([]int, len(frames))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return manifest.Frames[order[a]].Height > manifest.Frames[order[b]].Height
+	})
+
+	x, y, shelf := 0, 0, 0
+	for _, i := range order {
+		f := &manifest.Frames[i]
+
+		// не помещается - начинаем новую полку
+		if x > 0 && x+f.Width > limit {
+			x = 0
+			y += shelf + opts.Padding
+			shelf = 0
+		}
+
+		f.X, f.Y = x, y
+		x += f.Width + opts.Padding
+		shelf = max(shelf, f.Height)
+
+		manifest.Width = max(manifest.Width, f.X+f.Width)
+		manifest.Height = max(manifest.Height, f.Y+f.Height)
+	}
+
+	return manifest
+}
+
+// squareIcon вписывает изображение в квадрат size x size (с увеличением) по центру на прозрачном фоне.
+func squareIcon(img image.Image, size int) *image.NRGBA {
+	b := img.Bounds()
+
+	var scaled *image.NRGBA
+	if b.Dx() >= b.Dy() {
+		scaled = imaging.Resize(img, size, 0, imaging.Lanczos)
+	} else {
+		scaled = imaging.Resize(img, 0, size, imaging.Lanczos)
+	}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, size, size))
+
+	return imaging.PasteCenter(canvas, scaled)
+}
@@ -0,0 +1,313 @@
+package processor
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"math"
+	"math/rand/v2"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+	"github.com/google/uuid"
+)
+
+const (
+	// знак встраивается в яркость, приведенную к фиксированному размеру,
+	// поэтому переживает изменение размера изображения
+	markSize  = 512
+	markBlock = 8
+	markGrid  = markSize / markBlock
+
+	// шаг квантования DCT-коэффициента: больше - устойчивее, но заметнее
+	markStep = 24.0
+
+	// сколько раз уточнять встраивание после переноса на исходный размер
+	markIterations = 3
+
+	// сообщение: тип, длина, данные, crc32
+	markPayloadLen = 16
+	markMessageLen = 2 + markPayloadLen + 4
+	markBits       = markMessageLen * 8
+
+	markKindText byte = 0
+	markKindUUID byte = 1
+)
+
+var (
+	ErrMarkPayloadTooLong = errors.New("watermark payload is too long")
+
+	// среднечастотные коэффициенты блока, несущие бит
+	markCoefficients = [][2]int{{1, 2}, {2, 1}}
+
+	// базис DCT 8x8: markBasis[k][n]
+	markBasis = func() (b [markBlock][markBlock]float64) {
+		for k := 0; k < markBlock; k++ {
+			alpha := math.Sqrt(2.0 / markBlock)
+			if k == 0 {
+				alpha = math.Sqrt(1.0 / markBlock)
+			}
+			for n := 0; n < markBlock; n++ {
+				b[k][n] = alpha * math.Cos(float64(2*n+1)*float64(k)*math.Pi/(2*markBlock))
+			}
+		}
+		return b
+	}()
+
+	// фиксированное перемешивание: соседние блоки несут разные биты
+	markLayout = func() []int {
+		layout := make([]int, markGrid*markGrid)
+		for i := range layout {
+			layout[i] = i % markBits
+		}
+		rnd := rand.New(rand.NewPCG(0x1b873593, 0xcc9e2d51))
+		rnd.Shuffle(len(layout), func(i, j int) {
+			layout[i], layout[j] = layout[j], layout[i]
+		})
+		return layout
+	}()
+)
+
+// EmbedInvisibleWatermark встраивает payload (UUID или строку до 16 байт) в DCT-коэффициенты яркости.
+func (p *ImageProcessor) EmbedInvisibleWatermark(ctx context.Context, contentType string, data []byte, payload string) ([]byte, error) {
+	bits, err := markMessage(payload)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - EmbedInvisibleWatermark - markMessage: %w", err)
+	}
+
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - EmbedInvisibleWatermark - decodeImage: %w", err)
+	}
+
+	dst := imaging.Clone(img)
+
+	// после переноса на исходный размер коэффициенты сдвигаются - уточняем несколько раз
+	for i := 0; i < markIterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("ImageProcessor - EmbedInvisibleWatermark: %w", err)
+		}
+
+		delta, changed := markDelta(markPlane(dst), bits)
+		if !changed {
+			break
+		}
+		addPlane(dst, delta)
+	}
+
+	res, err := p.encodeImage(dst, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - EmbedInvisibleWatermark - encodeImage: %w", err)
+	}
+
+	return res, nil
+}
+
+// DetectInvisibleWatermark извлекает payload голосованием по всем блокам и проверяет crc.
+func (p *ImageProcessor) DetectInvisibleWatermark(ctx context.Context, data []byte) (dto.WatermarkDetection, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return dto.WatermarkDetection{}, fmt.Errorf("ImageProcessor - DetectInvisibleWatermark - decodeImage: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return dto.WatermarkDetection{}, fmt.Errorf("ImageProcessor - DetectInvisibleWatermark: %w", err)
+	}
+
+	plane := markPlane(imaging.Clone(img))
+
+	if err := ctx.Err(); err != nil {
+		return dto.WatermarkDetection{}, fmt.Errorf("ImageProcessor - DetectInvisibleWatermark: %w", err)
+	}
+
+	var votes [markBits]float64
+	var counts [markBits]int
+	forEachMarkBlock(func(block, bx, by int) {
+		for _, uv := range markCoefficients {
+			phase := markCoefficient(plane, bx, by, uv) / markStep
+			phase -= math.Floor(phase)
+			// +1 - ближе к решетке нуля, -1 - к решетке единицы
+			votes[markLayout[block]] += math.Cos(2 * math.Pi * phase)
+			counts[markLayout[block]]++
+		}
+	})
+
+	// уверенность считаем только для найденного знака: на гладких участках
+	// голоса смещены к нулю и без знака
+	var message [markMessageLen]byte
+	var confidence float64
+	for i, v := range votes {
+		if v < 0 {
+			message[i/8] |= 1 << (7 - i%8)
+		}
+		confidence += math.Abs(v) / float64(counts[i])
+	}
+	confidence /= markBits
+
+	payload, ok := parseMarkMessage(message[:])
+	if !ok {
+		return dto.WatermarkDetection{}, nil
+	}
+
+	return dto.WatermarkDetection{
+		Found:      true,
+		Payload:    payload,
+		Confidence: confidence,
+	}, nil
+}
+
+func markMessage(payload string) ([]byte, error) {
+	var msg [markMessageLen]byte
+
+	if id, err := uuid.Parse(payload); err == nil {
+		msg[0] = markKindUUID
+		msg[1] = byte(len(id))
+		copy(msg[2:], id[:])
+	} else {
+		if len(payload) > markPayloadLen {
+			return nil, ErrMarkPayloadTooLong
+		}
+		msg[0] = markKindText
+		msg[1] = byte(len(payload))
+		copy(msg[2:], payload)
+	}
+
+	binary.BigEndian.PutUint32(msg[2+markPayloadLen:], crc32.ChecksumIEEE(msg[:2+markPayloadLen]))
+
+	bits := make([]byte, markBits)
+	for i := range bits {
+		bits[i] = msg[i/8] >> (7 - i%8) & 1
+	}
+
+	return bits, nil
+}
+
+func parseMarkMessage(msg []byte) (string, bool) {
+	if crc32.ChecksumIEEE(msg[:2+markPayloadLen]) != binary.BigEndian.Uint32(msg[2+markPayloadLen:]) {
+		return "", false
+	}
+
+	n := int(msg[1])
+	if n > markPayloadLen {
+		return "", false
+	}
+
+	switch msg[0] {
+	case markKindUUID:
+		id, err := uuid.FromBytes(msg[2 : 2+n])
+		if err != nil {
+			return "", false
+		}
+		return id.String(), true
+	case markKindText:
+		return string(msg[2 : 2+n]), true
+	}
+
+	return "", false
+}
+
+// markPlane - яркость изображения, приведенная к markSize x markSize.
+func markPlane(img *image.NRGBA) []float64 {
+	gray := image.NewGray(img.Bounds())
+	forEachPixel(img, func(i int, px []uint8) {
+		gray.Pix[i] = luma(px)
+	})
+
+	resized := imaging.Resize(gray, markSize, markSize, imaging.Linear)
+
+	plane := make([]float64, markSize*markSize)
+	for i := range plane {
+		plane[i] = float64(resized.Pix[i*4])
+	}
+
+	return plane
+}
+
+// markDelta считает, насколько изменить плоскость, чтобы коэффициенты встали на решетку своих битов.
+func markDelta(plane []float64, bits []byte) ([]float64, bool) {
+	delta := make([]float64, len(plane))
+	changed := false
+
+	forEachMarkBlock(func(block, bx, by int) {
+		bit := float64(bits[markLayout[block]])
+
+		for _, uv := range markCoefficients {
+			c := markCoefficient(plane, bx, by, uv)
+			target := markStep*math.Round((c-bit*markStep/2)/markStep) + bit*markStep/2
+			d := target - c
+
+			// уже достаточно близко к центру решетки
+			if math.Abs(d) < markStep/16 {
+				continue
+			}
+			changed = true
+
+			for y := 0; y < markBlock; y++ {
+				for x := 0; x < markBlock; x++ {
+					delta[(by*markBlock+y)*markSize+bx*markBlock+x] += d * markBasis[uv[0]][y] * markBasis[uv[1]][x]
+				}
+			}
+		}
+	})
+
+	return delta, changed
+}
+
+func markCoefficient(plane []float64, bx, by int, uv [2]int) float64 {
+	var c float64
+	for y := 0; y < markBlock; y++ {
+		row := plane[(by*markBlock+y)*markSize+bx*markBlock:]
+		for x := 0; x < markBlock; x++ {
+			c += row[x] * markBasis[uv[0]][y] * markBasis[uv[1]][x]
+		}
+	}
+	return c
+}
+
+func forEachMarkBlock(f func(block, bx, by int)) {
+	for by := 0; by < markGrid; by++ {
+		for bx := 0; bx < markGrid; bx++ {
+			f(by*markGrid+bx, bx, by)
+		}
+	}
+}
+
+// addPlane растягивает плоскость markSize x markSize на изображение билинейно
+// и прибавляет ее ко всем каналам, то есть к яркости.
+func addPlane(img *image.NRGBA, plane []float64) {
+	b := img.Bounds()
+	sx := float64(markSize) / float64(b.Dx())
+	sy := float64(markSize) / float64(b.Dy())
+
+	forEachPixel(img, func(i int, px []uint8) {
+		x, y := i%b.Dx(), i/b.Dx()
+
+		fx := math.Min(math.Max((float64(x)+0.5)*sx-0.5, 0), markSize-1)
+		fy := math.Min(math.Max((float64(y)+0.5)*sy-0.5, 0), markSize-1)
+		x0, y0 := int(fx), int(fy)
+		x1, y1 := min(x0+1, markSize-1), min(y0+1, markSize-1)
+		tx, ty := fx-float64(x0), fy-float64(y0)
+
+		top := plane[y0*markSize+x0]*(1-tx) + plane[y0*markSize+x1]*tx
+		bottom := plane[y1*markSize+x0]*(1-tx) + plane[y1*markSize+x1]*tx
+		d := top*(1-ty) + bottom*ty
+
+		px[0] = clamp8(float64(px[0]) + d)
+		px[1] = clamp8(float64(px[1]) + d)
+		px[2] = clamp8(float64(px[2]) + d)
+	})
+}
+
+// forEachPixel вызывает f для каждого пикселя с его порядковым номером (срез R, G, B, A).
+func forEachPixel(img *image.NRGBA, f func(i int, px []uint8)) {
+	b := img.Bounds()
+	for y := 0; y < b.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+b.Dx()*4]
+		for x := 0; x < b.Dx(); x++ {
+			f(y*b.Dx()+x, row[x*4:x*4+4])
+		}
+	}
+}
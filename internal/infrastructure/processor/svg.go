@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+const (
+	// единица длины SVG - пиксель при 96 dpi
+	svgBaseDPI = 96.0
+
+	// размер, если в SVG не указаны ни width/height, ни viewBox
+	svgDefaultSize = 512
+
+	maxRasterSide = 10000
+	// 64 МБ на RGBA-холст; сторона в 10000 допустима только у узких изображений
+	maxRasterPixels = 16_000_000
+)
+
+// Rasterize отрисовывает SVG в растровое изображение и кодирует его в contentType.
+func (p *ImageProcessor) Rasterize(ctx context.Context, contentType string, data []byte, opts dto.RasterizeOptions) ([]byte, error) {
+	img, err := rasterizeSVG(data, opts)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Rasterize - rasterizeSVG: %w", err)
+	}
+
+	res, err := p.encodeImage(img, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Rasterize - encodeImage: %w", err)
+	}
+
+	return res, nil
+}
+
+func rasterizeSVG(data []byte, opts dto.RasterizeOptions) (*image.RGBA, error) {
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("oksvg.ReadIconStream: %w", err)
+	}
+
+	w, h := svgSize(icon.ViewBox.W, icon.ViewBox.H, opts)
+
+	icon.SetTarget(0, 0, float64(w), float64(h))
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	scanner := rasterx.NewScannerGV(w, h, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(w, h, scanner), 1)
+
+	return img, nil
+}
+
+// svgSize вычисляет размер результата: явный размер (с сохранением пропорций,
+// если задана одна сторона), иначе собственный размер с учетом dpi.
+func svgSize(vbW, vbH float64, opts dto.RasterizeOptions) (int, int) {
+	if vbW <= 0 || vbH <= 0 {
+		vbW, vbH = svgDefaultSize, svgDefaultSize
+	}
+
+	var w, h float64
+
+	switch {
+	case opts.Width > 0 && opts.Height > 0:
+		w, h = float64(opts.Width), float64(opts.Height)
+	case opts.Width > 0:
+		w = float64(opts.Width)
+		h = w * vbH / vbW
+	case opts.Height > 0:
+		h = float64(opts.Height)
+		w = h * vbW / vbH
+	default:
+		scale := 1.0
+		if opts.DPI > 0 {
+			scale = opts.DPI / svgBaseDPI
+		}
+		w, h = vbW*scale, vbH*scale
+	}
+
+	// защищаемся от гигантских холстов
+	if side := math.Max(w, h); side > maxRasterSide {
+		w, h = w*maxRasterSide/side, h*maxRasterSide/side
+	}
+	if area := w * h; area > maxRasterPixels {
+		scale := math.Sqrt(maxRasterPixels / area)
+		w, h = w*scale, h*scale
+	}
+
+	return max(1, int(math.Round(w))), max(1, int(math.Round(h)))
+}
+
+// isSVG грубо определяет, что данные - SVG-документ, а не растровое изображение.
+func isSVG(data []byte) bool {
+	head := data[:min(len(data), 1024)]
+	head = bytes.TrimLeft(head, "\xEF\xBB\xBF \t\r\n")
+
+	return (bytes.HasPrefix(head, []byte("<?xml")) || bytes.HasPrefix(head, []byte("<svg")) ||
+		bytes.HasPrefix(head, []byte("<!--"))) && bytes.Contains(head, []byte("<svg"))
+}
@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+)
+
+func TestSVGSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		vbW, vbH   float64
+		opts       dto.RasterizeOptions
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "own size", vbW: 300, vbH: 200, wantWidth: 300, wantHeight: 200},
+		{name: "no size", wantWidth: svgDefaultSize, wantHeight: svgDefaultSize},
+		{name: "width keeps ratio", vbW: 300, vbH: 200, opts: dto.RasterizeOptions{Width: 600}, wantWidth: 600, wantHeight: 400},
+		{name: "dpi", vbW: 96, vbH: 48, opts: dto.RasterizeOptions{DPI: 192}, wantWidth: 192, wantHeight: 96},
+		{name: "side clamp", vbW: 20000, vbH: 100, wantWidth: maxRasterSide, wantHeight: 50},
+		{name: "area clamp", vbW: 10000, vbH: 10000, wantWidth: 4000, wantHeight: 4000},
+		{name: "area clamp by dpi", vbW: 1000, vbH: 1000, opts: dto.RasterizeOptions{DPI: 1200}, wantWidth: 4000, wantHeight: 4000},
+		{name: "area clamp by explicit size", opts: dto.RasterizeOptions{Width: 8000, Height: 8000}, wantWidth: 4000, wantHeight: 4000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := svgSize(tt.vbW, tt.vbH, tt.opts)
+			if w != tt.wantWidth || h != tt.wantHeight {
+				t.Fatalf("svgSize() = %dx%d, want %dx%d", w, h, tt.wantWidth, tt.wantHeight)
+			}
+			if w*h > maxRasterPixels {
+				t.Fatalf("svgSize() = %dx%d exceeds %d pixels", w, h, maxRasterPixels)
+			}
+		})
+	}
+}
+
+func TestRasterizeSVGDeclaredSizeIsClamped(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="10000" height="10000" viewBox="0 0 10000 10000"><rect width="10" height="10"/></svg>`)
+
+	img, err := rasterizeSVG(svg, dto.RasterizeOptions{})
+	if err != nil {
+		t.Fatalf("rasterizeSVG() error = %v", err)
+	}
+
+	if b := img.Bounds(); b.Dx()*b.Dy() > maxRasterPixels {
+		t.Fatalf("rasterizeSVG() = %dx%d exceeds %d pixels", b.Dx(), b.Dy(), maxRasterPixels)
+	}
+}
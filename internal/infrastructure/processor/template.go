@@ -0,0 +1,212 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	layerImage = "image"
+	layerText  = "text"
+
+	AlignLeft   = "left"
+	AlignCenter = "center"
+	AlignRight  = "right"
+
+	VAlignTop    = "top"
+	VAlignMiddle = "middle"
+	VAlignBottom = "bottom"
+
+	defaultLineHeight = 1.2
+	ellipsis          = "…"
+)
+
+var ErrTemplateSource = errors.New("template layer refers to a missing source")
+
+// RenderTemplate рисует слои шаблона по порядку: изображения вписываются в свои области,
+// текст переносится по словам, выравнивается и обрезается многоточием.
+func (p *ImageProcessor) RenderTemplate(ctx context.Context, contentType string, sources [][]byte, opts dto.TemplateRender) ([]byte, error) {
+	bg, err := ParseHexColor(opts.Background)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - RenderTemplate - ParseHexColor: %w", err)
+	}
+
+	canvas := imaging.New(opts.Width, opts.Height, bg)
+
+	for _, layer := range opts.Layers {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("ImageProcessor - RenderTemplate: %w", err)
+		}
+
+		switch layer.Type {
+		case layerImage:
+			if layer.Source < 0 || layer.Source >= len(sources) {
+				return nil, fmt.Errorf("ImageProcessor - RenderTemplate: %w", ErrTemplateSource)
+			}
+
+			img, err := decodeImage(sources[layer.Source])
+			if err != nil {
+				return nil, fmt.Errorf("ImageProcessor - RenderTemplate - decodeImage: %w", err)
+			}
+
+			// картинка центрируется в своей области, как в ячейке коллажа
+			cell := fitCell(img, layer.Width, layer.Height, layer.Fit)
+			x := layer.X + (layer.Width-cell.Bounds().Dx())/2
+			y := layer.Y + (layer.Height-cell.Bounds().Dy())/2
+
+			draw.Draw(canvas, cell.Bounds().Add(image.Pt(x, y)), cell, cell.Bounds().Min, draw.Over)
+		case layerText:
+			if err := drawTextBox(canvas, layer); err != nil {
+				return nil, fmt.Errorf("ImageProcessor - RenderTemplate - drawTextBox: %w", err)
+			}
+		}
+	}
+
+	res, err := p.encodeImage(canvas, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - RenderTemplate - encodeImage: %w", err)
+	}
+
+	return res, nil
+}
+
+func drawTextBox(canvas *image.NRGBA, layer dto.TemplateLayerDraw) error {
+	c, err := ParseHexColor(layer.Color)
+	if err != nil {
+		return err
+	}
+
+	face, err := newFace(layer.Font, layer.FontSize)
+	if err != nil {
+		return err
+	}
+	defer face.Close()
+
+	lineHeight := layer.LineHeight
+	if lineHeight == 0 {
+		lineHeight = defaultLineHeight
+	}
+	step := layer.FontSize * lineHeight
+
+	metrics := face.Metrics()
+	ascent := float64(metrics.Ascent) / 64
+	descent := float64(metrics.Descent) / 64
+
+	// 1. перенос по словам и обрезка по числу строк
+	lines := wrapText(face, layer.Text, layer.Width)
+
+	maxLines := max(1, int((float64(layer.Height)-ascent-descent)/step)+1)
+	if layer.MaxLines > 0 {
+		maxLines = min(maxLines, layer.MaxLines)
+	}
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = ellipsize(face, lines[maxLines-1], layer.Width)
+	}
+
+	// 2. вертикальное выравнивание блока
+	block := float64(len(lines)-1)*step + ascent + descent
+	top := float64(layer.Y)
+	switch layer.VAlign {
+	case VAlignMiddle:
+		top += (float64(layer.Height) - block) / 2
+	case VAlignBottom:
+		top += float64(layer.Height) - block
+	}
+
+	// 3. строки с горизонтальным выравниванием
+	d := &font.Drawer{
+		Dst:  canvas,
+		Src:  image.NewUniform(c),
+		Face: face,
+	}
+	for i, line := range lines {
+		width := float64(d.MeasureString(line)) / 64
+
+		x := float64(layer.X)
+		switch layer.Align {
+		case AlignCenter:
+			x += (float64(layer.Width) - width) / 2
+		case AlignRight:
+			x += float64(layer.Width) - width
+		}
+
+		d.Dot = fixed.P(int(math.Round(x)), int(math.Round(top+ascent+float64(i)*step)))
+		d.DrawString(line)
+	}
+
+	return nil
+}
+
+// wrapText разбивает текст на строки не шире width. Переводы строк сохраняются,
+// слова длиннее строки режутся по символам.
+func wrapText(face font.Face, text string, width int) []string {
+	limit := fixed.I(width)
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if font.MeasureString(face, candidate) <= limit {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for font.MeasureString(face, word) > limit {
+				n := fitPrefix(face, word, limit)
+				lines = append(lines, word[:n])
+				word = word[n:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// fitPrefix - длина в байтах самого длинного префикса s не шире limit, но не меньше одного символа.
+func fitPrefix(face font.Face, s string, limit fixed.Int26_6) int {
+	_, n := utf8.DecodeRuneInString(s)
+	for i := range s {
+		if i <= n {
+			continue
+		}
+		if font.MeasureString(face, s[:i]) > limit {
+			break
+		}
+		n = i
+	}
+	return n
+}
+
+// ellipsize дописывает многоточие, убирая символы с конца, пока строка не станет не шире width.
+func ellipsize(face font.Face, line string, width int) string {
+	limit := fixed.I(width)
+
+	line = strings.TrimRight(line, " ")
+	for line != "" && font.MeasureString(face, line+ellipsis) > limit {
+		_, size := utf8.DecodeLastRuneInString(line)
+		line = strings.TrimRight(line[:len(line)-size], " ")
+	}
+
+	return line + ellipsis
+}
@@ -0,0 +1,192 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+)
+
+const (
+	tileLayoutXYZ = "xyz"
+	tileFormatPNG = "png"
+
+	dziDescriptorName = "image.dzi"
+	xyzDescriptorName = "tiles.json"
+)
+
+type xyzDescriptor struct {
+	Layout   string `json:"layout"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	TileSize int    `json:"tile_size"`
+	MinZoom  int    `json:"min_zoom"`
+	MaxZoom  int    `json:"max_zoom"`
+	Format   string `json:"format"`
+	URL      string `json:"url"`
+}
+
+// Tiles строит пирамиду тайлов в формате DeepZoom или XYZ.
+func (p *ImageProcessor) Tiles(ctx context.Context, data []byte, opts dto.TileOptions) (dto.TilePyramid, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return dto.TilePyramid{}, fmt.Errorf("ImageProcessor - Tiles - decodeImage: %w", err)
+	}
+
+	src := imaging.Clone(img)
+
+	contentType := "image/jpeg"
+	if opts.Format == tileFormatPNG {
+		contentType = "image/png"
+	}
+
+	var res dto.TilePyramid
+	if opts.Layout == tileLayoutXYZ {
+		res, err = p.xyzTiles(ctx, src, opts, contentType)
+	} else {
+		res, err = p.dziTiles(ctx, src, opts, contentType)
+	}
+	if err != nil {
+		return dto.TilePyramid{}, fmt.Errorf("ImageProcessor - Tiles: %w", err)
+	}
+
+	return res, nil
+}
+
+// dziTiles - уровни от 0 (1x1) до полного размера, на каждом размер вдвое больше предыдущего.
+func (p *ImageProcessor) dziTiles(ctx context.Context, src *image.NRGBA, opts dto.TileOptions, contentType string) (dto.TilePyramid, error) {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	maxLevel := int(math.Ceil(math.Log2(float64(max(w, h)))))
+
+	var files []dto.File
+
+	level := src
+	for l := maxLevel; l >= 0; l-- {
+		if err := ctx.Err(); err != nil {
+			return dto.TilePyramid{}, err
+		}
+
+		scale := math.Exp2(float64(maxLevel - l))
+		lw := max(int(math.Ceil(float64(w)/scale)), 1)
+		lh := max(int(math.Ceil(float64(h)/scale)), 1)
+		if lw != level.Bounds().Dx() || lh != level.Bounds().Dy() {
+			level = imaging.Resize(level, lw, lh, imaging.Linear)
+		}
+
+		for row := 0; row*opts.TileSize < lh; row++ {
+			for col := 0; col*opts.TileSize < lw; col++ {
+				// тайл расширяется на overlap пикселей в сторону соседей
+				rect := image.Rect(
+					col*opts.TileSize-opts.Overlap,
+					row*opts.TileSize-opts.Overlap,
+					(col+1)*opts.TileSize+opts.Overlap,
+					(row+1)*opts.TileSize+opts.Overlap,
+				).Intersect(level.Bounds())
+
+				tile, err := p.encodeImage(imaging.Crop(level, rect), contentType)
+				if err != nil {
+					return dto.TilePyramid{}, fmt.Errorf("encodeImage: %w", err)
+				}
+
+				files = append(files, dto.File{
+					Name:        fmt.Sprintf("image_files/%d/%d_%d.%s", l, col, row, opts.Format),
+					Data:        tile,
+					ContentType: contentType,
+				})
+			}
+		}
+	}
+
+	descriptor := []byte(fmt.Sprintf(
+		`<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<Image xmlns="http://schemas.microsoft.com/deepzoom/2008" Format="%s" Overlap="%d" TileSize="%d">`+
+			`<Size Width="%d" Height="%d"/></Image>`+"\n",
+		opts.Format, opts.Overlap, opts.TileSize, w, h,
+	))
+
+	files = append(files, dto.File{Name: dziDescriptorName, Data: descriptor, ContentType: "application/xml"})
+
+	return dto.TilePyramid{
+		Descriptor:            descriptor,
+		DescriptorName:        dziDescriptorName,
+		DescriptorContentType: "application/xml",
+		Files:                 files,
+		Width:                 w,
+		Height:                h,
+		Levels:                maxLevel + 1,
+	}, nil
+}
+
+// xyzTiles - на уровне z изображение вписано в 2^z тайлов по большей стороне,
+// на последнем уровне - в собственном размере. Крайние тайлы дополняются до полного размера.
+func (p *ImageProcessor) xyzTiles(ctx context.Context, src *image.NRGBA, opts dto.TileOptions, contentType string) (dto.TilePyramid, error) {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	maxZoom := max(int(math.Ceil(math.Log2(float64(max(w, h))/float64(opts.TileSize)))), 0)
+
+	var files []dto.File
+
+	for z := 0; z <= maxZoom; z++ {
+		if err := ctx.Err(); err != nil {
+			return dto.TilePyramid{}, err
+		}
+
+		scale := math.Exp2(float64(maxZoom - z))
+		lw := max(int(math.Round(float64(w)/scale)), 1)
+		lh := max(int(math.Round(float64(h)/scale)), 1)
+
+		level := src
+		if lw != w || lh != h {
+			level = imaging.Resize(src, lw, lh, imaging.Linear)
+		}
+
+		for y := 0; y*opts.TileSize < lh; y++ {
+			for x := 0; x*opts.TileSize < lw; x++ {
+				rect := image.Rect(x*opts.TileSize, y*opts.TileSize, (x+1)*opts.TileSize, (y+1)*opts.TileSize)
+
+				tile := imaging.New(opts.TileSize, opts.TileSize, image.Transparent)
+				tile = imaging.Paste(tile, imaging.Crop(level, rect), image.Pt(0, 0))
+
+				encoded, err := p.encodeImage(tile, contentType)
+				if err != nil {
+					return dto.TilePyramid{}, fmt.Errorf("encodeImage: %w", err)
+				}
+
+				files = append(files, dto.File{
+					Name:        fmt.Sprintf("%d/%d/%d.%s", z, x, y, opts.Format),
+					Data:        encoded,
+					ContentType: contentType,
+				})
+			}
+		}
+	}
+
+	descriptor, err := json.Marshal(xyzDescriptor{
+		Layout:   tileLayoutXYZ,
+		Width:    w,
+		Height:   h,
+		TileSize: opts.TileSize,
+		MinZoom:  0,
+		MaxZoom:  maxZoom,
+		Format:   opts.Format,
+		URL:      "{z}/{x}/{y}." + opts.Format,
+	})
+	if err != nil {
+		return dto.TilePyramid{}, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	files = append(files, dto.File{Name: xyzDescriptorName, Data: descriptor, ContentType: "application/json"})
+
+	return dto.TilePyramid{
+		Descriptor:            descriptor,
+		DescriptorName:        xyzDescriptorName,
+		DescriptorContentType: "application/json",
+		Files:                 files,
+		Width:                 w,
+		Height:                h,
+		Levels:                maxZoom + 1,
+	}, nil
+}
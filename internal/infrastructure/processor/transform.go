@@ -0,0 +1,332 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+)
+
+const (
+	InterpolationBilinear = "bilinear"
+	InterpolationBicubic  = "bicubic"
+
+	// определитель меньше этого считаем вырожденным
+	transformEpsilon = 1e-9
+)
+
+var (
+	ErrDegenerateTransform = errors.New("transform is degenerate")
+	ErrTransformTooLarge   = errors.New("transformed image is too large")
+)
+
+// homography - проективное отображение точек результата в точки исходника:
+// x = (a*u + b*v + c) / (g*u + h*v + 1), y = (d*u + e*v + f) / (g*u + h*v + 1).
+type homography struct {
+	a, b, c, d, e, f, g, h float64
+}
+
+func (m homography) apply(u, v float64) (float64, float64, bool) {
+	w := m.g*u + m.h*v + 1
+	if math.Abs(w) < transformEpsilon {
+		return 0, 0, false
+	}
+	return (m.a*u + m.b*v + m.c) / w, (m.d*u + m.e*v + m.f) / w, true
+}
+
+// Transform применяет аффинное преобразование или перспективное выпрямление области.
+// Пиксели вне исходника заливаются цветом fill.
+func (p *ImageProcessor) Transform(ctx context.Context, contentType string, data []byte, opts dto.TransformOptions) ([]byte, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Transform - decodeImage: %w", err)
+	}
+
+	src := imaging.Clone(img)
+
+	fill := color.NRGBA{}
+	if opts.Fill != "" {
+		fill, err = ParseHexColor(opts.Fill)
+		if err != nil {
+			return nil, fmt.Errorf("ImageProcessor - Transform - ParseHexColor: %w", err)
+		}
+	}
+
+	// 1. обратное отображение: пиксель результата -> точка исходника
+	m, width, height, err := transformMapping(opts, src.Bounds())
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Transform - transformMapping: %w", err)
+	}
+
+	// 2. выборка
+	sample := sampleBilinear
+	if opts.Interpolation == InterpolationBicubic {
+		sample = sampleBicubic
+	}
+
+	dst, err := warp(ctx, src, m, width, height, fill, sample)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Transform - warp: %w", err)
+	}
+
+	res, err := p.encodeImage(dst, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("ImageProcessor - Transform - encodeImage: %w", err)
+	}
+
+	return res, nil
+}
+
+// TransformSize возвращает размер результата Transform для исходника width x height без декодирования,
+// чтобы вызывающий мог отказать до выделения холста.
+func (p *ImageProcessor) TransformSize(ctx context.Context, width, height int, opts dto.TransformOptions) (int, int, error) {
+	_, w, h, err := transformMapping(opts, image.Rect(0, 0, width, height))
+	if err != nil {
+		return 0, 0, fmt.Errorf("ImageProcessor - TransformSize - transformMapping: %w", err)
+	}
+
+	return w, h, nil
+}
+
+// transformMapping выбирает отображение по опциям и проверяет размер результата.
+func transformMapping(opts dto.TransformOptions, bounds image.Rectangle) (homography, int, int, error) {
+	var m homography
+	var width, height int
+	var err error
+	if len(opts.Corners) == 4 {
+		m, width, height, err = perspectiveMapping(opts.Corners, opts.Width, opts.Height)
+	} else {
+		m, width, height, err = affineMapping(opts.Matrix, bounds, opts.Width, opts.Height)
+	}
+	if err != nil {
+		return homography{}, 0, 0, err
+	}
+
+	if width < 1 || height < 1 || width > maxRasterSide || height > maxRasterSide || width*height > maxRasterPixels {
+		return homography{}, 0, 0, ErrTransformTooLarge
+	}
+
+	return m, width, height, nil
+}
+
+// affineMapping обращает прямую матрицу. Без заданного размера холст - это
+// габариты преобразованного изображения, сдвинутые в начало координат.
+func affineMapping(matrix []float64, bounds image.Rectangle, width, height int) (homography, int, int, error) {
+	if len(matrix) != 6 {
+		return homography{}, 0, 0, ErrDegenerateTransform
+	}
+
+	a, b, c, d, e, f := matrix[0], matrix[1], matrix[2], matrix[3], matrix[4], matrix[5]
+
+	det := a*e - b*d
+	if math.Abs(det) < transformEpsilon {
+		return homography{}, 0, 0, ErrDegenerateTransform
+	}
+
+	var offX, offY float64
+	if width == 0 || height == 0 {
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		for _, pt := range [][2]float64{
+			{0, 0},
+			{float64(bounds.Dx()), 0},
+			{float64(bounds.Dx()), float64(bounds.Dy())},
+			{0, float64(bounds.Dy())},
+		} {
+			x := a*pt[0] + b*pt[1] + c
+			y := d*pt[0] + e*pt[1] + f
+			minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+			minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+		}
+
+		if width == 0 {
+			offX = minX
+			width = int(math.Ceil(maxX - minX))
+		}
+		if height == 0 {
+			offY = minY
+			height = int(math.Ceil(maxY - minY))
+		}
+	}
+
+	// обратная матрица с учетом сдвига холста
+	ia, ib := e/det, -b/det
+	id, ie := -d/det, a/det
+	cx, cy := offX-c, offY-f
+
+	return homography{
+		a: ia, b: ib, c: ia*cx + ib*cy,
+		d: id, e: ie, f: id*cx + ie*cy,
+	}, width, height, nil
+}
+
+// perspectiveMapping отображает прямоугольник width x height на четырехугольник corners.
+// Без заданного размера берутся длины противоположных сторон области.
+func perspectiveMapping(corners []dto.Point, width, height int) (homography, int, int, error) {
+	dist := func(p, q dto.Point) float64 {
+		return math.Hypot(q.X-p.X, q.Y-p.Y)
+	}
+
+	if width == 0 {
+		width = int(math.Round(math.Max(dist(corners[0], corners[1]), dist(corners[3], corners[2]))))
+	}
+	if height == 0 {
+		height = int(math.Round(math.Max(dist(corners[0], corners[3]), dist(corners[1], corners[2]))))
+	}
+	if width < 1 || height < 1 {
+		return homography{}, 0, 0, ErrDegenerateTransform
+	}
+
+	// единичный квадрат -> четырехугольник (Heckbert)
+	x0, y0 := corners[0].X, corners[0].Y
+	x1, y1 := corners[1].X, corners[1].Y
+	x2, y2 := corners[2].X, corners[2].Y
+	x3, y3 := corners[3].X, corners[3].Y
+
+	dx1, dy1 := x1-x2, y1-y2
+	dx2, dy2 := x3-x2, y3-y2
+	dx3, dy3 := x0-x1+x2-x3, y0-y1+y2-y3
+
+	var m homography
+	if math.Abs(dx3) < transformEpsilon && math.Abs(dy3) < transformEpsilon {
+		// параллелограмм - перспективы нет
+		m = homography{
+			a: x1 - x0, b: x3 - x0, c: x0,
+			d: y1 - y0, e: y3 - y0, f: y0,
+		}
+	} else {
+		den := dx1*dy2 - dx2*dy1
+		if math.Abs(den) < transformEpsilon {
+			return homography{}, 0, 0, ErrDegenerateTransform
+		}
+		g := (dx3*dy2 - dx2*dy3) / den
+		h := (dx1*dy3 - dx3*dy1) / den
+		m = homography{
+			a: x1 - x0 + g*x1, b: x3 - x0 + h*x3, c: x0,
+			d: y1 - y0 + g*y1, e: y3 - y0 + h*y3, f: y0,
+			g: g, h: h,
+		}
+	}
+
+	if math.Abs(m.a*m.e-m.b*m.d) < transformEpsilon {
+		return homography{}, 0, 0, ErrDegenerateTransform
+	}
+
+	// масштабируем вход: пиксели результата вместо единичного квадрата
+	sx, sy := 1/float64(width), 1/float64(height)
+	m.a, m.d, m.g = m.a*sx, m.d*sx, m.g*sx
+	m.b, m.e, m.h = m.b*sy, m.e*sy, m.h*sy
+
+	return m, width, height, nil
+}
+
+// sampler возвращает предумноженный на альфу цвет в точке исходника,
+// точки вне изображения берутся цвета fill.
+type sampler func(src *image.NRGBA, x, y float64, fill [4]float64) [4]float64
+
+func warp(ctx context.Context, src *image.NRGBA, m homography, width, height int, fill color.NRGBA, sample sampler) (*image.NRGBA, error) {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	fa := float64(fill.A) / 0xff
+	pFill := [4]float64{float64(fill.R) * fa, float64(fill.G) * fa, float64(fill.B) * fa, float64(fill.A)}
+
+	for y := 0; y < height; y++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		row := dst.Pix[y*dst.Stride:]
+		for x := 0; x < width; x++ {
+			px := row[x*4 : x*4+4]
+
+			// центры пикселей: +0.5 на входе, -0.5 на выходе
+			sx, sy, ok := m.apply(float64(x)+0.5, float64(y)+0.5)
+			if !ok {
+				px[0], px[1], px[2], px[3] = fill.R, fill.G, fill.B, fill.A
+				continue
+			}
+
+			c := sample(src, sx-0.5, sy-0.5, pFill)
+			a := clamp8(c[3])
+			if a == 0 {
+				px[0], px[1], px[2], px[3] = 0, 0, 0, 0
+				continue
+			}
+			k := float64(0xff) / c[3]
+			px[0], px[1], px[2], px[3] = clamp8(c[0]*k), clamp8(c[1]*k), clamp8(c[2]*k), a
+		}
+	}
+
+	return dst, nil
+}
+
+// premultiplied - пиксель исходника с предумноженной альфой, вне изображения - fill.
+func premultiplied(src *image.NRGBA, x, y int, fill [4]float64) [4]float64 {
+	b := src.Bounds()
+	if x < 0 || y < 0 || x >= b.Dx() || y >= b.Dy() {
+		return fill
+	}
+
+	px := src.Pix[y*src.Stride+x*4:]
+	a := float64(px[3]) / 0xff
+	return [4]float64{float64(px[0]) * a, float64(px[1]) * a, float64(px[2]) * a, float64(px[3])}
+}
+
+func sampleBilinear(src *image.NRGBA, x, y float64, fill [4]float64) [4]float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	tx, ty := x-x0, y-y0
+	ix, iy := int(x0), int(y0)
+
+	p00 := premultiplied(src, ix, iy, fill)
+	p10 := premultiplied(src, ix+1, iy, fill)
+	p01 := premultiplied(src, ix, iy+1, fill)
+	p11 := premultiplied(src, ix+1, iy+1, fill)
+
+	var c [4]float64
+	for ch := range c {
+		top := p00[ch]*(1-tx) + p10[ch]*tx
+		bottom := p01[ch]*(1-tx) + p11[ch]*tx
+		c[ch] = top*(1-ty) + bottom*ty
+	}
+	return c
+}
+
+func sampleBicubic(src *image.NRGBA, x, y float64, fill [4]float64) [4]float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	ix, iy := int(x0), int(y0)
+	wx, wy := catmullRom(x-x0), catmullRom(y-y0)
+
+	var c [4]float64
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			px := premultiplied(src, ix+i-1, iy+j-1, fill)
+			w := wx[i] * wy[j]
+			for ch := range c {
+				c[ch] += px[ch] * w
+			}
+		}
+	}
+
+	// ядро дает выбросы на резких границах
+	c[3] = math.Min(math.Max(c[3], 0), 0xff)
+	for ch := 0; ch < 3; ch++ {
+		c[ch] = math.Min(math.Max(c[ch], 0), c[3])
+	}
+	return c
+}
+
+// catmullRom - веса четырех соседних отсчетов для дробного смещения t.
+func catmullRom(t float64) [4]float64 {
+	t2, t3 := t*t, t*t*t
+	return [4]float64{
+		(-t3 + 2*t2 - t) / 2,
+		(3*t3 - 5*t2 + 2) / 2,
+		(-3*t3 + 4*t2 + t) / 2,
+		(t3 - t2) / 2,
+	}
+}
@@ -0,0 +1,132 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/disintegration/imaging"
+)
+
+// Trim обрезает однотонную рамку и возвращает результат вместе с оставленной областью.
+func (p *ImageProcessor) Trim(ctx context.Context, contentType string, data []byte, opts dto.TrimOptions) ([]byte, image.Rectangle, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, image.Rectangle{}, fmt.Errorf("ImageProcessor - Trim - decodeImage: %w", err)
+	}
+
+	src := imaging.Clone(img)
+
+	rect := contentBounds(src, opts.Tolerance)
+	if rect.Empty() {
+		// изображение целиком одного цвета - обрезать нечего
+		rect = src.Bounds()
+	}
+
+	rect = image.Rect(
+		rect.Min.X-opts.Padding,
+		rect.Min.Y-opts.Padding,
+		rect.Max.X+opts.Padding,
+		rect.Max.Y+opts.Padding,
+	).Intersect(src.Bounds())
+
+	trimmed := imaging.Crop(src, rect)
+
+	res, err := p.encodeImage(trimmed, contentType)
+	if err != nil {
+		return nil, image.Rectangle{}, fmt.Errorf("ImageProcessor - Trim - encodeImage: %w", err)
+	}
+
+	return res, rect, nil
+}
+
+// contentBounds ищет область, отличающуюся от цвета рамки больше чем на tolerance.
+// Цвет рамки - тот, что совпадает в большинстве углов изображения.
+func contentBounds(img *image.NRGBA, tolerance int) image.Rectangle {
+	b := img.Bounds()
+	border := borderColor(img)
+
+	isBorder := func(x, y int) bool {
+		return colorDistance(img.Pix[img.PixOffset(x, y):img.PixOffset(x, y)+4], border) <= tolerance
+	}
+
+	rowIsBorder := func(y int) bool {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if !isBorder(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+
+	colIsBorder := func(x, minY, maxY int) bool {
+		for y := minY; y < maxY; y++ {
+			if !isBorder(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+
+	top := b.Min.Y
+	for top < b.Max.Y && rowIsBorder(top) {
+		top++
+	}
+
+	bottom := b.Max.Y
+	for bottom > top && rowIsBorder(bottom-1) {
+		bottom--
+	}
+
+	left := b.Min.X
+	for left < b.Max.X && colIsBorder(left, top, bottom) {
+		left++
+	}
+
+	right := b.Max.X
+	for right > left && colIsBorder(right-1, top, bottom) {
+		right--
+	}
+
+	return image.Rect(left, top, right, bottom)
+}
+
+func borderColor(img *image.NRGBA) []uint8 {
+	b := img.Bounds()
+	corners := [][]uint8{
+		img.Pix[img.PixOffset(b.Min.X, b.Min.Y):][:4],
+		img.Pix[img.PixOffset(b.Max.X-1, b.Min.Y):][:4],
+		img.Pix[img.PixOffset(b.Min.X, b.Max.Y-1):][:4],
+		img.Pix[img.PixOffset(b.Max.X-1, b.Max.Y-1):][:4],
+	}
+
+	best, bestVotes := corners[0], 0
+	for _, c := range corners {
+		votes := 0
+		for _, other := range corners {
+			if colorDistance(c, other) == 0 {
+				votes++
+			}
+		}
+		if votes > bestVotes {
+			best, bestVotes = c, votes
+		}
+	}
+
+	return best
+}
+
+// colorDistance - максимальное отличие по каналам (R, G, B, A).
+func colorDistance(a, b []uint8) int {
+	d := 0
+	for i := 0; i < 4; i++ {
+		diff := int(a[i]) - int(b[i])
+		if diff < 0 {
+			diff = -diff
+		}
+		d = max(d, diff)
+	}
+
+	return d
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
 	"github.com/andreyxaxa/Image-Processor/internal/entity"
 	"github.com/google/uuid"
 )
@@ -15,13 +16,33 @@ type (
 		Download(ctx context.Context, key string) (io.ReadCloser, error)
 		DownloadBytes(ctx context.Context, key string) ([]byte, error)
 		Delete(ctx context.Context, key string) error
+		DeletePrefix(ctx context.Context, prefix string) error
 	}
 
 	ImageMetadataRepo interface {
 		Create(ctx context.Context, image *entity.Image) error
 		GetByID(ctx context.Context, id uuid.UUID) (*entity.Image, error)
+		GetByIDs(ctx context.Context, IDs uuid.UUIDs) ([]*entity.Image, error)
 		GetProcessedKeyByID(ctx context.Context, id uuid.UUID) (string, string, error)
+		List(ctx context.Context, filter dto.ImageFilter) ([]*entity.Image, error)
 		Update(ctx context.Context, image *entity.Image) error
+		SetCurrentVersion(ctx context.Context, version *entity.ImageVersion) error
+		MarkAsPending(ctx context.Context, id uuid.UUID, operation string) error
+		MarkAsFailedBatch(ctx context.Context, IDs uuid.UUIDs, reason string) error
+		Delete(ctx context.Context, id uuid.UUID) error
+	}
+
+	ImageVersionRepo interface {
+		Create(ctx context.Context, version *entity.ImageVersion) error
+		GetByNumber(ctx context.Context, imageID uuid.UUID, number int) (*entity.ImageVersion, error)
+		List(ctx context.Context, imageID uuid.UUID) ([]*entity.ImageVersion, error)
+	}
+
+	TemplateRepo interface {
+		Create(ctx context.Context, template *entity.Template) error
+		GetByID(ctx context.Context, id uuid.UUID) (*entity.Template, error)
+		List(ctx context.Context) ([]*entity.Template, error)
+		Update(ctx context.Context, template *entity.Template) error
 		Delete(ctx context.Context, id uuid.UUID) error
 	}
 
@@ -32,7 +53,7 @@ type (
 		MarkAsProcessedBatch(ctx context.Context, IDs uuid.UUIDs) error
 		MarkAsFailedBatch(ctx context.Context, IDs uuid.UUIDs) error
 		IncrementRetryCountBatch(ctx context.Context, IDs uuid.UUIDs) error
-		MarkMaxRetriesAsFailed(ctx context.Context, maxRetries int) error
+		MarkMaxRetriesAsFailed(ctx context.Context, maxRetries int) (uuid.UUIDs, error)
 		DeleteOldProcessedAndFailed(ctx context.Context) (int64, error)
 	}
 
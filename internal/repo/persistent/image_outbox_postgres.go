@@ -202,7 +202,8 @@ func (r *OutboxImageMetadataRepo) MarkAsFailedBatch(ctx context.Context, IDs uui
 	return nil
 }
 
-func (r *OutboxImageMetadataRepo) MarkMaxRetriesAsFailed(ctx context.Context, maxRetries int) error {
+// MarkMaxRetriesAsFailed возвращает ID изображений, чьи события так и не удалось отправить.
+func (r *OutboxImageMetadataRepo) MarkMaxRetriesAsFailed(ctx context.Context, maxRetries int) (uuid.UUIDs, error) {
 	sql, args, err := r.Builder.
 		Update(outboxTable).
 		Set(outboxStatusColumn, entity.Failed).
@@ -210,19 +211,34 @@ func (r *OutboxImageMetadataRepo) MarkMaxRetriesAsFailed(ctx context.Context, ma
 			squirrel.Eq{outboxStatusColumn: string(entity.Pending)},
 			squirrel.GtOrEq{outboxRetryCountColumn: maxRetries},
 		}).
+		Suffix("RETURNING " + outboxAggregateIDColumn).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("OutboxImageMetadataRepo - MarkMaxRetriesAsFailed - r.Builder.ToSql: %w", err)
+		return nil, fmt.Errorf("OutboxImageMetadataRepo - MarkMaxRetriesAsFailed - r.Builder.ToSql: %w", err)
 	}
 
 	executor := r.GetExecutor(ctx)
 
-	_, err = executor.Exec(ctx, sql, args...)
+	rows, err := executor.Query(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("OutboxImageMetadataRepo - MarkMaxRetriesAsFailed - executor.Exec: %w", err)
+		return nil, fmt.Errorf("OutboxImageMetadataRepo - MarkMaxRetriesAsFailed - executor.Query: %w", err)
 	}
+	defer rows.Close()
 
-	return nil
+	var IDs uuid.UUIDs
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("OutboxImageMetadataRepo - MarkMaxRetriesAsFailed - rows.Scan: %w", err)
+		}
+		IDs = append(IDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("OutboxImageMetadataRepo - MarkMaxRetriesAsFailed - rows.Err: %w", err)
+	}
+
+	return IDs, nil
 }
 
 func (r *OutboxImageMetadataRepo) IncrementRetryCountBatch(ctx context.Context, IDs uuid.UUIDs) error {
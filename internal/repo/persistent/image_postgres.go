@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
 	"github.com/andreyxaxa/Image-Processor/internal/entity"
 	"github.com/andreyxaxa/Image-Processor/pkg/postgres"
 	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
@@ -18,15 +20,22 @@ const (
 	imagesTable = "images"
 
 	// Columns
-	idColumn           = "id"
-	originalKeyColumn  = "original_key"
-	processedKeyColumn = "processed_key"
-	originalNameColumn = "original_name"
-	contentTypeColumn  = "content_type"
-	sizeColumn         = "size"
-	statusColumn       = "status"
-	createdAtColumn    = "created_at"
-	processedAtColumn  = "processed_at"
+	idColumn                   = "id"
+	originalKeyColumn          = "original_key"
+	processedKeyColumn         = "processed_key"
+	originalNameColumn         = "original_name"
+	contentTypeColumn          = "content_type"
+	processedContentTypeColumn = "processed_content_type"
+	sizeColumn                 = "size"
+	statusColumn               = "status"
+	metadataColumn             = "metadata"
+	createdAtColumn            = "created_at"
+	processedAtColumn          = "processed_at"
+	qualityGradeColumn         = "quality_grade"
+	sharpnessColumn            = "sharpness"
+	operationColumn            = "operation"
+	failureReasonColumn        = "failure_reason"
+	currentVersionIDColumn     = "current_version_id"
 )
 
 type ImageMetadataRepo struct {
@@ -47,6 +56,7 @@ func (r *ImageMetadataRepo) Create(ctx context.Context, image *entity.Image) err
 			contentTypeColumn,
 			sizeColumn,
 			statusColumn,
+			operationColumn,
 			createdAtColumn,
 		).
 		Values(
@@ -56,6 +66,7 @@ func (r *ImageMetadataRepo) Create(ctx context.Context, image *entity.Image) err
 			image.ContentType,
 			image.Size,
 			image.Status,
+			image.Operation,
 			image.CreatedAt,
 		).ToSql()
 	if err != nil {
@@ -81,8 +92,13 @@ func (r *ImageMetadataRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 			processedKeyColumn,
 			originalNameColumn,
 			contentTypeColumn,
+			processedContentTypeColumn,
 			sizeColumn,
 			statusColumn,
+			fmt.Sprintf("COALESCE(%s, '')", operationColumn),
+			failureReasonColumn,
+			metadataColumn,
+			currentVersionIDColumn,
 			createdAtColumn,
 			processedAtColumn,
 		).
@@ -102,8 +118,13 @@ func (r *ImageMetadataRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 		&image.ProcessedKey,
 		&image.OriginalName,
 		&image.ContentType,
+		&image.ProcessedContentType,
 		&image.Size,
 		&image.Status,
+		&image.Operation,
+		&image.FailureReason,
+		&image.Metadata,
+		&image.CurrentVersionID,
 		&image.CreatedAt,
 		&image.ProcessedAt,
 	)
@@ -117,9 +138,77 @@ func (r *ImageMetadataRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 	return &image, nil
 }
 
+func (r *ImageMetadataRepo) GetByIDs(ctx context.Context, IDs uuid.UUIDs) ([]*entity.Image, error) {
+	sql, args, err := r.Builder.
+		Select(
+			idColumn,
+			originalKeyColumn,
+			processedKeyColumn,
+			originalNameColumn,
+			contentTypeColumn,
+			processedContentTypeColumn,
+			sizeColumn,
+			statusColumn,
+			fmt.Sprintf("COALESCE(%s, '')", operationColumn),
+			failureReasonColumn,
+			metadataColumn,
+			currentVersionIDColumn,
+			createdAtColumn,
+			processedAtColumn,
+		).
+		From(imagesTable).
+		Where(squirrel.Eq{idColumn: IDs}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ImageMetadataRepo - GetByIDs - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	rows, err := executor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ImageMetadataRepo - GetByIDs - executor.Query: %w", err)
+	}
+	defer rows.Close()
+
+	images := make([]*entity.Image, 0, len(IDs))
+	for rows.Next() {
+		var image entity.Image
+		err = rows.Scan(
+			&image.ID,
+			&image.OriginalKey,
+			&image.ProcessedKey,
+			&image.OriginalName,
+			&image.ContentType,
+			&image.ProcessedContentType,
+			&image.Size,
+			&image.Status,
+			&image.Operation,
+			&image.FailureReason,
+			&image.Metadata,
+			&image.CurrentVersionID,
+			&image.CreatedAt,
+			&image.ProcessedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ImageMetadataRepo - GetByIDs - rows.Scan: %w", err)
+		}
+		images = append(images, &image)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ImageMetadataRepo - GetByIDs - rows.Err: %w", err)
+	}
+
+	return images, nil
+}
+
 func (r *ImageMetadataRepo) GetProcessedKeyByID(ctx context.Context, id uuid.UUID) (string, string, error) {
 	sql, args, err := r.Builder.
-		Select(processedKeyColumn, contentTypeColumn).
+		Select(
+			processedKeyColumn,
+			fmt.Sprintf("COALESCE(%s, %s)", processedContentTypeColumn, contentTypeColumn),
+		).
 		From(imagesTable).
 		Where(squirrel.And{
 			squirrel.Eq{idColumn: id},
@@ -150,8 +239,14 @@ func (r *ImageMetadataRepo) Update(ctx context.Context, image *entity.Image) err
 	sql, args, err := r.Builder.
 		Update(imagesTable).
 		Set(processedKeyColumn, image.ProcessedKey).
+		Set(processedContentTypeColumn, image.ProcessedContentType).
 		Set(statusColumn, image.Status).
+		Set(metadataColumn, image.Metadata).
 		Set(processedAtColumn, image.ProcessedAt).
+		Set(failureReasonColumn, image.FailureReason).
+		Set(currentVersionIDColumn, image.CurrentVersionID).
+		Set(qualityGradeColumn, qualityGrade(image.Metadata.Quality)).
+		Set(sharpnessColumn, sharpness(image.Metadata.Quality)).
 		Where(squirrel.Eq{idColumn: image.ID}).
 		ToSql()
 	if err != nil {
@@ -194,3 +289,228 @@ func (r *ImageMetadataRepo) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// MarkAsPending ставит изображение в очередь на повторную обработку. Изображение, которое уже
+// в очереди, не трогается: условие в UPDATE атомарно, поэтому две параллельные переобработки
+// не получат по задаче и не перепишут processed_key вперемешку.
+func (r *ImageMetadataRepo) MarkAsPending(ctx context.Context, id uuid.UUID, operation string) error {
+	sql, args, err := r.Builder.
+		Update(imagesTable).
+		Set(statusColumn, entity.Pending).
+		Set(operationColumn, operation).
+		Set(failureReasonColumn, nil).
+		Where(squirrel.And{
+			squirrel.Eq{idColumn: id},
+			squirrel.NotEq{statusColumn: string(entity.Pending)},
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ImageMetadataRepo - MarkAsPending - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	tag, err := executor.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ImageMetadataRepo - MarkAsPending - executor.Exec: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("ImageMetadataRepo - MarkAsPending: %w", errs.ErrImageBusy)
+	}
+
+	return nil
+}
+
+// SetCurrentVersion делает версию текущим результатом изображения. Пока по изображению есть
+// задача в очереди, версия не переключается - воркер все равно перезапишет результат.
+func (r *ImageMetadataRepo) SetCurrentVersion(ctx context.Context, version *entity.ImageVersion) error {
+	sql, args, err := r.Builder.
+		Update(imagesTable).
+		Set(processedKeyColumn, version.Key).
+		Set(processedContentTypeColumn, version.ContentType).
+		Set(metadataColumn, version.Metadata).
+		Set(currentVersionIDColumn, version.ID).
+		Set(statusColumn, entity.Processed).
+		Set(operationColumn, version.Operation).
+		Set(failureReasonColumn, nil).
+		Set(qualityGradeColumn, qualityGrade(version.Metadata.Quality)).
+		Set(sharpnessColumn, sharpness(version.Metadata.Quality)).
+		Where(squirrel.And{
+			squirrel.Eq{idColumn: version.ImageID},
+			squirrel.NotEq{statusColumn: string(entity.Pending)},
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ImageMetadataRepo - SetCurrentVersion - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	tag, err := executor.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ImageMetadataRepo - SetCurrentVersion - executor.Exec: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("ImageMetadataRepo - SetCurrentVersion: %w", errs.ErrImageBusy)
+	}
+
+	return nil
+}
+
+// MarkAsFailedBatch переводит еще не обработанные изображения в failed с причиной.
+func (r *ImageMetadataRepo) MarkAsFailedBatch(ctx context.Context, IDs uuid.UUIDs, reason string) error {
+	sql, args, err := r.Builder.
+		Update(imagesTable).
+		Set(statusColumn, entity.Failed).
+		Set(failureReasonColumn, reason).
+		Where(squirrel.And{
+			squirrel.Eq{idColumn: IDs},
+			squirrel.Eq{statusColumn: string(entity.Pending)},
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ImageMetadataRepo - MarkAsFailedBatch - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	_, err = executor.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ImageMetadataRepo - MarkAsFailedBatch - executor.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// List возвращает изображения по фильтру, новые первыми.
+func (r *ImageMetadataRepo) List(ctx context.Context, filter dto.ImageFilter) ([]*entity.Image, error) {
+	where := squirrel.And{}
+	if filter.Status != "" {
+		where = append(where, squirrel.Eq{statusColumn: filter.Status})
+	}
+	if filter.ContentType != "" {
+		where = append(where, squirrel.Eq{contentTypeColumn: filter.ContentType})
+	}
+	if filter.CreatedFrom != nil {
+		where = append(where, squirrel.GtOrEq{createdAtColumn: *filter.CreatedFrom})
+	}
+	if filter.CreatedTo != nil {
+		where = append(where, squirrel.Lt{createdAtColumn: *filter.CreatedTo})
+	}
+	if filter.NamePrefix != "" {
+		where = append(where, squirrel.Like{originalNameColumn: likePrefix(filter.NamePrefix)})
+	}
+	if filter.MinSize != nil {
+		where = append(where, squirrel.GtOrEq{sizeColumn: *filter.MinSize})
+	}
+	if filter.MaxSize != nil {
+		where = append(where, squirrel.LtOrEq{sizeColumn: *filter.MaxSize})
+	}
+	if filter.QualityGrade != "" {
+		where = append(where, squirrel.Eq{qualityGradeColumn: filter.QualityGrade})
+	}
+	if filter.QualityIssue != "" {
+		where = append(where, squirrel.Expr(fmt.Sprintf("%s->'quality'->'issues' @> ?::jsonb", metadataColumn),
+			fmt.Sprintf("[%q]", filter.QualityIssue)))
+	}
+	if filter.MinSharpness != nil {
+		where = append(where, squirrel.GtOrEq{sharpnessColumn: *filter.MinSharpness})
+	}
+	if filter.MaxSharpness != nil {
+		where = append(where, squirrel.LtOrEq{sharpnessColumn: *filter.MaxSharpness})
+	}
+	// keyset-пагинация: сравнение кортежей идет по idx_created_at_status, id разделяет одинаковое время
+	if filter.After != nil {
+		where = append(where, squirrel.Expr(
+			fmt.Sprintf("(%s, %s) < (?::timestamp, ?::uuid)", createdAtColumn, idColumn),
+			filter.After.CreatedAt, filter.After.ID,
+		))
+	}
+
+	sql, args, err := r.Builder.
+		Select(
+			idColumn,
+			originalKeyColumn,
+			processedKeyColumn,
+			originalNameColumn,
+			contentTypeColumn,
+			processedContentTypeColumn,
+			sizeColumn,
+			statusColumn,
+			fmt.Sprintf("COALESCE(%s, '')", operationColumn),
+			failureReasonColumn,
+			metadataColumn,
+			currentVersionIDColumn,
+			createdAtColumn,
+			processedAtColumn,
+		).
+		From(imagesTable).
+		Where(where).
+		OrderBy(createdAtColumn+" DESC", idColumn+" DESC").
+		Limit(uint64(filter.Limit)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ImageMetadataRepo - List - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	rows, err := executor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ImageMetadataRepo - List - executor.Query: %w", err)
+	}
+	defer rows.Close()
+
+	images := make([]*entity.Image, 0, filter.Limit)
+	for rows.Next() {
+		var image entity.Image
+		err = rows.Scan(
+			&image.ID,
+			&image.OriginalKey,
+			&image.ProcessedKey,
+			&image.OriginalName,
+			&image.ContentType,
+			&image.ProcessedContentType,
+			&image.Size,
+			&image.Status,
+			&image.Operation,
+			&image.FailureReason,
+			&image.Metadata,
+			&image.CurrentVersionID,
+			&image.CreatedAt,
+			&image.ProcessedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ImageMetadataRepo - List - rows.Scan: %w", err)
+		}
+		images = append(images, &image)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ImageMetadataRepo - List - rows.Err: %w", err)
+	}
+
+	return images, nil
+}
+
+// likePrefix экранирует спецсимволы LIKE, чтобы префикс искался буквально
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+}
+
+// оценка качества дублируется в колонки, чтобы фильтровать по индексу
+func qualityGrade(q *entity.Quality) *string {
+	if q == nil {
+		return nil
+	}
+	return &q.Grade
+}
+
+func sharpness(q *entity.Quality) *float64 {
+	if q == nil {
+		return nil
+	}
+	return &q.Sharpness
+}
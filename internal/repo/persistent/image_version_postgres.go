@@ -0,0 +1,179 @@
+package persistent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/andreyxaxa/Image-Processor/pkg/postgres"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// Table
+	imageVersionsTable = "image_versions"
+
+	// Columns
+	versionImageIDColumn = "image_id"
+	versionNumberColumn  = "number"
+	versionKeyColumn     = "key"
+	versionSpecColumn    = "spec"
+)
+
+type ImageVersionRepo struct {
+	*postgres.Postgres
+}
+
+func NewImageVersionRepo(pg *postgres.Postgres) *ImageVersionRepo {
+	return &ImageVersionRepo{pg}
+}
+
+// Create сохраняет версию и присваивает ей следующий номер в пределах изображения.
+func (r *ImageVersionRepo) Create(ctx context.Context, version *entity.ImageVersion) error {
+	number := squirrel.Expr(
+		fmt.Sprintf("(SELECT COALESCE(MAX(%s), 0) + 1 FROM %s WHERE %s = ?)",
+			versionNumberColumn, imageVersionsTable, versionImageIDColumn),
+		version.ImageID,
+	)
+
+	sql, args, err := r.Builder.
+		Insert(imageVersionsTable).
+		Columns(
+			idColumn,
+			versionImageIDColumn,
+			versionNumberColumn,
+			versionKeyColumn,
+			contentTypeColumn,
+			sizeColumn,
+			operationColumn,
+			versionSpecColumn,
+			metadataColumn,
+			createdAtColumn,
+		).
+		Values(
+			version.ID,
+			version.ImageID,
+			number,
+			version.Key,
+			version.ContentType,
+			version.Size,
+			version.Operation,
+			version.Spec,
+			version.Metadata,
+			version.CreatedAt,
+		).
+		Suffix("RETURNING " + versionNumberColumn).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ImageVersionRepo - Create - r.Builder.ToSql(): %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	err = executor.QueryRow(ctx, sql, args...).Scan(&version.Number)
+	if err != nil {
+		return fmt.Errorf("ImageVersionRepo - Create - executor.QueryRow: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ImageVersionRepo) GetByNumber(ctx context.Context, imageID uuid.UUID, number int) (*entity.ImageVersion, error) {
+	sql, args, err := r.selectVersions().
+		Where(squirrel.Eq{
+			versionImageIDColumn: imageID,
+			versionNumberColumn:  number,
+		}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ImageVersionRepo - GetByNumber - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	version, err := scanVersion(executor.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("ImageVersionRepo - GetByNumber: %w", errs.ErrRecordNotFound)
+		}
+		return nil, fmt.Errorf("ImageVersionRepo - GetByNumber - executor.QueryRow: %w", err)
+	}
+
+	return version, nil
+}
+
+// List возвращает версии изображения, новые первыми.
+func (r *ImageVersionRepo) List(ctx context.Context, imageID uuid.UUID) ([]*entity.ImageVersion, error) {
+	sql, args, err := r.selectVersions().
+		Where(squirrel.Eq{versionImageIDColumn: imageID}).
+		OrderBy(versionNumberColumn + " DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ImageVersionRepo - List - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	rows, err := executor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ImageVersionRepo - List - executor.Query: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make([]*entity.ImageVersion, 0)
+	for rows.Next() {
+		version, err := scanVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ImageVersionRepo - List - rows.Scan: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ImageVersionRepo - List - rows.Err: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (r *ImageVersionRepo) selectVersions() squirrel.SelectBuilder {
+	return r.Builder.
+		Select(
+			idColumn,
+			versionImageIDColumn,
+			versionNumberColumn,
+			versionKeyColumn,
+			contentTypeColumn,
+			sizeColumn,
+			operationColumn,
+			versionSpecColumn,
+			metadataColumn,
+			createdAtColumn,
+		).
+		From(imageVersionsTable)
+}
+
+func scanVersion(row pgx.Row) (*entity.ImageVersion, error) {
+	var version entity.ImageVersion
+	err := row.Scan(
+		&version.ID,
+		&version.ImageID,
+		&version.Number,
+		&version.Key,
+		&version.ContentType,
+		&version.Size,
+		&version.Operation,
+		&version.Spec,
+		&version.Metadata,
+		&version.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
@@ -3,12 +3,15 @@ package persistent
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/andreyxaxa/Image-Processor/pkg/s3client"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 type ImageRepo struct {
@@ -58,6 +61,10 @@ func (r *ImageRepo) Download(ctx context.Context, key string) (io.ReadCloser, er
 		Key:    aws.String(key),
 	})
 	if err != nil {
+		var noKey *types.NoSuchKey
+		if errors.As(err, &noKey) {
+			return nil, fmt.Errorf("ImageRepo - Download - r.c.Client.GetObject: %w", errs.ErrRecordNotFound)
+		}
 		return nil, fmt.Errorf("ImageRepo - Download - r.c.Client.GetObject: %w", err)
 	}
 
@@ -93,3 +100,41 @@ func (r *ImageRepo) Delete(ctx context.Context, key string) error {
 
 	return nil
 }
+
+// DeletePrefix удаляет все объекты с ключами, начинающимися с prefix.
+func (r *ImageRepo) DeletePrefix(ctx context.Context, prefix string) error {
+	paginator := s3.NewListObjectsV2Paginator(r.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(r.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("ImageRepo - DeletePrefix - paginator.NextPage: %w", err)
+		}
+
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		// страница - не больше 1000 ключей, как и лимит DeleteObjects
+		objects := make([]types.ObjectIdentifier, 0, len(page.Contents))
+		for _, obj := range page.Contents {
+			objects = append(objects, types.ObjectIdentifier{Key: obj.Key})
+		}
+
+		_, err = r.Client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(r.bucket),
+			Delete: &types.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("ImageRepo - DeletePrefix - r.c.Client.DeleteObjects: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package persistent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/andreyxaxa/Image-Processor/pkg/postgres"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// Table
+	templatesTable = "templates"
+
+	// Columns
+	templateNameColumn       = "name"
+	templateWidthColumn      = "width"
+	templateHeightColumn     = "height"
+	templateBackgroundColumn = "background"
+	templateLayersColumn     = "layers"
+	updatedAtColumn          = "updated_at"
+)
+
+type TemplateRepo struct {
+	*postgres.Postgres
+}
+
+func NewTemplateRepo(pg *postgres.Postgres) *TemplateRepo {
+	return &TemplateRepo{pg}
+}
+
+func (r *TemplateRepo) Create(ctx context.Context, template *entity.Template) error {
+	sql, args, err := r.Builder.
+		Insert(templatesTable).
+		Columns(
+			idColumn,
+			templateNameColumn,
+			templateWidthColumn,
+			templateHeightColumn,
+			templateBackgroundColumn,
+			templateLayersColumn,
+			createdAtColumn,
+			updatedAtColumn,
+		).
+		Values(
+			template.ID,
+			template.Name,
+			template.Width,
+			template.Height,
+			template.Background,
+			template.Layers,
+			template.CreatedAt,
+			template.UpdatedAt,
+		).ToSql()
+	if err != nil {
+		return fmt.Errorf("TemplateRepo - Create - r.Builder.ToSql(): %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	_, err = executor.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TemplateRepo - Create - executor.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TemplateRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Template, error) {
+	sql, args, err := r.selectTemplates().
+		Where(squirrel.Eq{idColumn: id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TemplateRepo - GetByID - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	template, err := scanTemplate(executor.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("TemplateRepo - GetByID: %w", errs.ErrRecordNotFound)
+		}
+		return nil, fmt.Errorf("TemplateRepo - GetByID - executor.QueryRow: %w", err)
+	}
+
+	return template, nil
+}
+
+func (r *TemplateRepo) List(ctx context.Context) ([]*entity.Template, error) {
+	sql, args, err := r.selectTemplates().
+		OrderBy(createdAtColumn + " DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TemplateRepo - List - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	rows, err := executor.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TemplateRepo - List - executor.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*entity.Template
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("TemplateRepo - List - rows.Scan: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("TemplateRepo - List - rows.Err: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (r *TemplateRepo) Update(ctx context.Context, template *entity.Template) error {
+	sql, args, err := r.Builder.
+		Update(templatesTable).
+		Set(templateNameColumn, template.Name).
+		Set(templateWidthColumn, template.Width).
+		Set(templateHeightColumn, template.Height).
+		Set(templateBackgroundColumn, template.Background).
+		Set(templateLayersColumn, template.Layers).
+		Set(updatedAtColumn, template.UpdatedAt).
+		Where(squirrel.Eq{idColumn: template.ID}).
+		Suffix("RETURNING " + createdAtColumn).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TemplateRepo - Update - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	err = executor.QueryRow(ctx, sql, args...).Scan(&template.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("TemplateRepo - Update: %w", errs.ErrRecordNotFound)
+		}
+		return fmt.Errorf("TemplateRepo - Update - executor.QueryRow: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TemplateRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	sql, args, err := r.Builder.
+		Delete(templatesTable).
+		Where(squirrel.Eq{idColumn: id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TemplateRepo - Delete - r.Builder.ToSql: %w", err)
+	}
+
+	executor := r.GetExecutor(ctx)
+
+	tag, err := executor.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TemplateRepo - Delete - executor.Exec: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("TemplateRepo - Delete: %w", errs.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (r *TemplateRepo) selectTemplates() squirrel.SelectBuilder {
+	return r.Builder.
+		Select(
+			idColumn,
+			templateNameColumn,
+			templateWidthColumn,
+			templateHeightColumn,
+			templateBackgroundColumn,
+			templateLayersColumn,
+			createdAtColumn,
+			updatedAtColumn,
+		).
+		From(templatesTable)
+}
+
+func scanTemplate(row pgx.Row) (*entity.Template, error) {
+	var template entity.Template
+	err := row.Scan(
+		&template.ID,
+		&template.Name,
+		&template.Width,
+		&template.Height,
+		&template.Background,
+		&template.Layers,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
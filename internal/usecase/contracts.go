@@ -19,20 +19,47 @@ type (
 			size int64,
 			operation dto.Operation,
 		) (*entity.Image, error)
-		UploadProcessedImage(ctx context.Context, data []byte, imageID uuid.UUID) error
+		CreateCollage(ctx context.Context, IDs uuid.UUIDs, contentType string, layout dto.CollageLayout) (*entity.Image, error)
+		CreateSprite(ctx context.Context, IDs uuid.UUIDs, opts dto.SpriteOptions) (*entity.Image, error)
+		CreateTemplate(ctx context.Context, template *entity.Template) error
+		GetTemplate(ctx context.Context, id uuid.UUID) (*entity.Template, error)
+		ListTemplates(ctx context.Context) ([]*entity.Template, error)
+		UpdateTemplate(ctx context.Context, template *entity.Template) error
+		DeleteTemplate(ctx context.Context, id uuid.UUID) error
+		RenderTemplate(
+			ctx context.Context,
+			template *entity.Template,
+			images map[string]uuid.UUID,
+			texts map[string]string,
+			contentType string,
+		) (*entity.Image, error)
+		FetchURL(ctx context.Context, rawURL string) (dto.RemoteFile, error)
+		ReprocessImage(ctx context.Context, id uuid.UUID, operation dto.Operation) (*entity.Image, error)
+		UploadProcessedImage(ctx context.Context, result dto.Result, imageID uuid.UUID, spec dto.OperationSpec) error
+		ListVersions(ctx context.Context, id uuid.UUID) ([]*entity.ImageVersion, error)
+		GetVersion(ctx context.Context, id uuid.UUID, number int) (*entity.ImageVersion, error)
+		SetCurrentVersion(ctx context.Context, id uuid.UUID, number int) (*entity.ImageVersion, error)
 		DownloadImage(ctx context.Context, key string) (io.ReadCloser, error)
 		DownloadImageBytes(ctx context.Context, key string) ([]byte, error)
+		DownloadFile(ctx context.Context, id uuid.UUID, name string) (io.ReadCloser, error)
+		DownloadTile(ctx context.Context, id uuid.UUID, name string) (io.ReadCloser, string, error)
 		DeleteImage(ctx context.Context, id uuid.UUID) error
+		GetImage(ctx context.Context, id uuid.UUID) (*entity.Image, error)
+		ListImages(ctx context.Context, filter dto.ImageFilter) (dto.ImagePage, error)
 		GetProcessedKeyByID(ctx context.Context, id uuid.UUID) (string, string, error)
 		GetPendingEvents(ctx context.Context, maxRetries, limit int) ([]*entity.OutboxEvent, error)
 		MarkAsProcessingBatch(ctx context.Context, events []*entity.OutboxEvent) error
 		MarkAsProcessedBatch(ctx context.Context, events []*entity.OutboxEvent) error
 		IncrementRetryCountBatch(ctx context.Context, events []*entity.OutboxEvent) error
 		MarkMaxRetriesAsFailed(ctx context.Context, maxRetries int) error
+		MarkImageFailed(ctx context.Context, id uuid.UUID, reason string) error
 		CleanupOutbox(ctx context.Context) error
 	}
 
 	ImageProcessorUseCase interface {
-		Process(ctx context.Context, contentType string, task dto.Task) ([]byte, error)
+		Process(ctx context.Context, contentType string, task dto.Task) (dto.Result, error)
+		TransformSize(ctx context.Context, width, height int, opts dto.TransformOptions) (int, int, error)
+		Compare(ctx context.Context, a, b []byte, withDiff bool) (dto.Comparison, error)
+		DetectWatermark(ctx context.Context, data []byte) (dto.WatermarkDetection, error)
 	}
 )
@@ -0,0 +1,122 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/google/uuid"
+)
+
+const (
+	collageOperation = "collage"
+	spriteOperation  = "sprite"
+)
+
+func (uc *ImageUseCase) CreateCollage(
+	ctx context.Context,
+	IDs uuid.UUIDs,
+	contentType string,
+	layout dto.CollageLayout,
+) (*entity.Image, error) {
+	operation := dto.Operation{
+		Operation: collageOperation,
+		Collage:   &layout,
+	}
+
+	name := fmt.Sprintf("collage.%s", strings.TrimPrefix(contentType, "image/"))
+
+	image, err := uc.createFromSources(ctx, IDs, name, contentType, operation)
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - CreateCollage - uc.createFromSources: %w", err)
+	}
+
+	return image, nil
+}
+
+// CreateSprite собирает спрайт-лист в png; манифест с координатами кадров сохраняется рядом с результатом.
+func (uc *ImageUseCase) CreateSprite(ctx context.Context, IDs uuid.UUIDs, opts dto.SpriteOptions) (*entity.Image, error) {
+	// кадры в манифесте называются id исходников
+	opts.IDs = IDs.Strings()
+
+	operation := dto.Operation{
+		Operation: spriteOperation,
+		Sprite:    &opts,
+	}
+
+	image, err := uc.createFromSources(ctx, IDs, "sprite.png", "image/png", operation)
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - CreateSprite - uc.createFromSources: %w", err)
+	}
+
+	return image, nil
+}
+
+// createFromSources создает изображение, которое воркер соберет из оригиналов IDs.
+func (uc *ImageUseCase) createFromSources(
+	ctx context.Context,
+	IDs uuid.UUIDs,
+	name string,
+	contentType string,
+	operation dto.Operation,
+) (*entity.Image, error) {
+	imageID := uuid.New()
+
+	image := &entity.Image{
+		ID:           imageID,
+		OriginalName: name,
+		ContentType:  contentType,
+		Status:       entity.Pending,
+		Operation:    operation.Operation,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	// в единой транзакции
+	err := uc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// 1. получаем ключи оригиналов всех исходников
+		sources, err := uc.metadataRepo.GetByIDs(ctx, IDs)
+		if err != nil {
+			return fmt.Errorf("uc.metadataRepo.GetByIDs: %w", err)
+		}
+
+		byID := make(map[uuid.UUID]*entity.Image, len(sources))
+		for _, src := range sources {
+			byID[src.ID] = src
+		}
+
+		// ключи в порядке, в котором изображения переданы - это порядок ячеек/кадров
+		sourceKeys := make([]string, 0, len(IDs))
+		for _, id := range IDs {
+			src, ok := byID[id]
+			if !ok || src.OriginalKey == "" {
+				return fmt.Errorf("image %s: %w", id, errs.ErrRecordNotFound)
+			}
+			sourceKeys = append(sourceKeys, src.OriginalKey)
+		}
+
+		// 2. записываем метаданные нового изображения
+		if err := uc.metadataRepo.Create(ctx, image); err != nil {
+			return fmt.Errorf("uc.metadataRepo.Create: %w", err)
+		}
+
+		// 3. записываем задачу в аутбокс
+		event, err := uc.createOutboxEvent(imageID, "", contentType, operation, sourceKeys)
+		if err != nil {
+			return fmt.Errorf("uc.createOutboxEvent: %w", err)
+		}
+		if err := uc.outboxMetadataRepo.Create(ctx, event); err != nil {
+			return fmt.Errorf("uc.outboxMetadataRepo.Create: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("uc.transactor.WithinTransaction: %w", err)
+	}
+
+	return image, nil
+}
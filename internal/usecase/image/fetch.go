@@ -0,0 +1,19 @@
+package image
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+)
+
+// FetchURL скачивает файл по ссылке клиента. Проверка содержимого остается за вызывающим,
+// как и для обычной загрузки.
+func (uc *ImageUseCase) FetchURL(ctx context.Context, rawURL string) (dto.RemoteFile, error) {
+	file, err := uc.fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		return dto.RemoteFile{}, fmt.Errorf("ImageUseCase - FetchURL - uc.fetcher.Fetch: %w", err)
+	}
+
+	return file, nil
+}
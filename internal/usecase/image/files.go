@@ -0,0 +1,100 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+// сколько файлов загружаем в S3 одновременно
+const uploadConcurrency = 8
+
+// filesPrefix - префикс файлов результата. У каждой версии свой префикс внутри префикса изображения,
+// изображения, обработанные до появления версий, хранят файлы прямо в префиксе изображения.
+func filesPrefix(imageID uuid.UUID, versionID *uuid.UUID) string {
+	if versionID == nil {
+		return fmt.Sprintf("files/%s/", imageID)
+	}
+	return fmt.Sprintf("files/%s/%s/", imageID, versionID)
+}
+
+// DownloadTile отдает файл пирамиды тайлов и его имя. name - путь внутри префикса изображения,
+// пустой путь - дескриптор.
+func (uc *ImageUseCase) DownloadTile(ctx context.Context, id uuid.UUID, name string) (io.ReadCloser, string, error) {
+	image, err := uc.metadataRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("ImageUseCase - DownloadTile - uc.metadataRepo.GetByID: %w", err)
+	}
+
+	if image.Metadata.Tiles == nil {
+		return nil, "", fmt.Errorf("ImageUseCase - DownloadTile: %w", errs.ErrRecordNotFound)
+	}
+
+	if name == "" {
+		name = image.Metadata.Tiles.Descriptor
+	}
+
+	body, err := uc.imageRepo.Download(ctx, filesPrefix(id, image.CurrentVersionID)+name)
+	if err != nil {
+		return nil, "", fmt.Errorf("ImageUseCase - DownloadTile - uc.imageRepo.Download: %w", err)
+	}
+
+	return body, name, nil
+}
+
+// DownloadFile отдает дополнительный файл результата (манифест спрайта, иконку).
+func (uc *ImageUseCase) DownloadFile(ctx context.Context, id uuid.UUID, name string) (io.ReadCloser, error) {
+	image, err := uc.metadataRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - DownloadFile - uc.metadataRepo.GetByID: %w", err)
+	}
+
+	if !slices.Contains(image.Metadata.Files, name) {
+		return nil, fmt.Errorf("ImageUseCase - DownloadFile: %w", errs.ErrRecordNotFound)
+	}
+
+	body, err := uc.imageRepo.Download(ctx, filesPrefix(id, image.CurrentVersionID)+name)
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - DownloadFile - uc.imageRepo.Download: %w", err)
+	}
+
+	return body, nil
+}
+
+func (uc *ImageUseCase) uploadFiles(ctx context.Context, prefix string, files []dto.File) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(uploadConcurrency)
+
+	for _, f := range files {
+		g.Go(func() error {
+			err := uc.imageRepo.UploadBytes(gctx, prefix+f.Name, f.Data, f.ContentType, int64(len(f.Data)))
+			if err != nil {
+				return fmt.Errorf("uc.imageRepo.UploadBytes: %w", err)
+			}
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// cleanupProcessed удаляет результат обработки из S3, если сохранить его до конца не удалось.
+func (uc *ImageUseCase) cleanupProcessed(ctx context.Context, processedKey, prefix string, withFiles bool) {
+	if err := uc.imageRepo.Delete(ctx, processedKey); err != nil {
+		uc.logger.Error(err, "ImageUseCase - UploadProcessedImage - uc.imageRepo.Delete")
+	}
+
+	if !withFiles {
+		return
+	}
+
+	if err := uc.imageRepo.DeletePrefix(ctx, prefix); err != nil {
+		uc.logger.Error(err, "ImageUseCase - UploadProcessedImage - uc.imageRepo.DeletePrefix")
+	}
+}
@@ -15,15 +15,37 @@ func (uc *ImageUseCase) createOutboxEvent(
 	originalKey string,
 	contentType string,
 	operation dto.Operation,
+	sourceKeys []string,
 ) (*entity.OutboxEvent, error) {
+	// по умолчанию невидимый водяной знак несет ID изображения
+	if operation.Mark != nil && operation.Mark.Payload == "" {
+		mark := *operation.Mark
+		mark.Payload = imageID.String()
+		operation.Mark = &mark
+	}
+
 	payload := map[string]interface{}{
-		"id":           imageID,
-		"original_key": originalKey,
-		"content_type": contentType,
-		"operation":    operation.Operation,
-		"width":        operation.Width,
-		"height":       operation.Height,
-		"text":         operation.Text,
+		"id":                  imageID,
+		"original_key":        originalKey,
+		"content_type":        contentType,
+		"operation":           operation.Operation,
+		"width":               operation.Width,
+		"height":              operation.Height,
+		"text":                operation.Text,
+		"source_keys":         sourceKeys,
+		"collage":             operation.Collage,
+		"quantize":            operation.Quantize,
+		"enhance":             operation.Enhance,
+		"trim":                operation.Trim,
+		"invisible_watermark": operation.Mark,
+		"tiles":               operation.Tiles,
+		"sprite":              operation.Sprite,
+		"remove_background":   operation.Keying,
+		"transform":           operation.Transform,
+		"annotate":            operation.Annotate,
+		"template":            operation.Template,
+		"output_metadata":     operation.Output,
+		"rasterize":           operation.Rasterize,
 	}
 
 	b, err := json.Marshal(payload)
@@ -40,3 +62,19 @@ func (uc *ImageUseCase) createOutboxEvent(
 		RetryCount:  0,
 	}, nil
 }
+
+// mergeMetadata переносит в dst заполненные поля src, не затирая остальные.
+func mergeMetadata(dst *entity.Metadata, src entity.Metadata) {
+	if src.Trim != nil {
+		dst.Trim = src.Trim
+	}
+	if src.Tiles != nil {
+		dst.Tiles = src.Tiles
+	}
+	if src.Files != nil {
+		dst.Files = src.Files
+	}
+	if src.Quality != nil {
+		dst.Quality = src.Quality
+	}
+}
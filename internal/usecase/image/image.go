@@ -8,16 +8,23 @@ import (
 
 	"github.com/andreyxaxa/Image-Processor/internal/dto"
 	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/andreyxaxa/Image-Processor/internal/infrastructure"
 	"github.com/andreyxaxa/Image-Processor/internal/repo"
 	"github.com/andreyxaxa/Image-Processor/pkg/logger"
 	"github.com/google/uuid"
 )
 
+// причина для изображений, задачу которых не удалось отправить в очередь за все попытки
+const failureReasonNotQueued = "processing task could not be queued"
+
 type ImageUseCase struct {
 	imageRepo          repo.ImageRepo
 	metadataRepo       repo.ImageMetadataRepo
 	outboxMetadataRepo repo.OutboxImageMetadataRepo
+	templateRepo       repo.TemplateRepo
+	versionRepo        repo.ImageVersionRepo
 	transactor         repo.Transactor
+	fetcher            infrastructure.URLFetcher
 
 	logger logger.Interface
 }
@@ -26,14 +33,20 @@ func New(
 	imageRepo repo.ImageRepo,
 	metadataRepo repo.ImageMetadataRepo,
 	outboxRepo repo.OutboxImageMetadataRepo,
+	templateRepo repo.TemplateRepo,
+	versionRepo repo.ImageVersionRepo,
 	transactor repo.Transactor,
+	fetcher infrastructure.URLFetcher,
 	l logger.Interface,
 ) *ImageUseCase {
 	return &ImageUseCase{
 		imageRepo:          imageRepo,
 		metadataRepo:       metadataRepo,
 		outboxMetadataRepo: outboxRepo,
+		templateRepo:       templateRepo,
+		versionRepo:        versionRepo,
 		transactor:         transactor,
+		fetcher:            fetcher,
 		logger:             l,
 	}
 }
@@ -63,7 +76,8 @@ func (uc *ImageUseCase) UploadNewImage(
 		ContentType:  contentType,
 		Size:         size,
 		Status:       entity.Pending,
-		CreatedAt:    time.Now(),
+		Operation:    operation.Operation,
+		CreatedAt:    time.Now().UTC(), // колонка без зоны - пишем UTC
 	}
 
 	// 2. в единой транзакции
@@ -74,7 +88,7 @@ func (uc *ImageUseCase) UploadNewImage(
 		}
 
 		// 2.2 записываем метаданные в аутбокс таблицу
-		event, err := uc.createOutboxEvent(imageID, originalKey, contentType, operation)
+		event, err := uc.createOutboxEvent(imageID, originalKey, contentType, operation, nil)
 		if err != nil {
 			return fmt.Errorf("ImageUseCase - UploadNewImage - uc.createOutboxEvent: %w", err)
 		}
@@ -98,36 +112,85 @@ func (uc *ImageUseCase) UploadNewImage(
 	return image, nil
 }
 
-func (uc *ImageUseCase) UploadProcessedImage(ctx context.Context, data []byte, imageID uuid.UUID) error {
+// UploadProcessedImage сохраняет результат обработки новой версией и делает ее текущей.
+// Версии неизменяемы: у каждой свой ключ в S3 и свой префикс файлов.
+func (uc *ImageUseCase) UploadProcessedImage(
+	ctx context.Context,
+	result dto.Result,
+	imageID uuid.UUID,
+	spec dto.OperationSpec,
+) error {
 	// 1. получим текущие метаданные, чтобы не затереть лишнее
 	image, err := uc.metadataRepo.GetByID(ctx, imageID)
 	if err != nil {
 		return fmt.Errorf("ImageUseCase - UploadProcessedImage - uc.metadataRepo.GetByID: %w", err)
 	}
 
-	// 2. генерируем ключ и сохраняем в S3
-	processedKey := fmt.Sprintf("processed/%s", imageID)
-	err = uc.imageRepo.UploadBytes(ctx, processedKey, data, image.ContentType, int64(len(data)))
+	// 2. генерируем ключ версии и сохраняем в S3
+	versionID := uuid.New()
+	processedKey := fmt.Sprintf("processed/%s/%s", imageID, versionID)
+	prefix := filesPrefix(imageID, &versionID)
+	err = uc.imageRepo.UploadBytes(ctx, processedKey, result.Data, result.ContentType, int64(len(result.Data)))
 	if err != nil {
 		return fmt.Errorf("ImageUseCase - UploadProcessedImage - uc.imageRepo.UploadBytes: %w", err)
 	}
 
-	// 3. модифицируем сущность
+	// 2.1 сопутствующие файлы (тайлы) - под префиксом версии
+	if len(result.Files) > 0 {
+		err = uc.uploadFiles(ctx, prefix, result.Files)
+		if err != nil {
+			uc.cleanupProcessed(ctx, processedKey, prefix, true)
+			return fmt.Errorf("ImageUseCase - UploadProcessedImage - uc.uploadFiles: %w", err)
+		}
+	}
+
+	// 3. сведения от предыдущей операции к новому результату не относятся
+	metadata := image.Metadata
+	metadata.Trim, metadata.Tiles, metadata.Files = nil, nil, nil
+	mergeMetadata(&metadata, result.Metadata)
+
+	now := time.Now().UTC()
+	params := spec.Params
+	if params == nil {
+		params = []byte("{}")
+	}
+	version := &entity.ImageVersion{
+		ID:          versionID,
+		ImageID:     imageID,
+		Key:         processedKey,
+		ContentType: result.ContentType,
+		Size:        int64(len(result.Data)),
+		Operation:   spec.Operation,
+		Spec:        params,
+		Metadata:    metadata,
+		CreatedAt:   now,
+	}
+
+	// 4. модифицируем сущность
 	image.ProcessedKey = &processedKey
+	image.ProcessedContentType = &result.ContentType
+	image.Metadata = metadata
+	image.CurrentVersionID = &versionID
 	image.Status = entity.Processed
-	now := time.Now()
+	image.FailureReason = nil
 	image.ProcessedAt = &now
 
-	// 4. обновляем метаданные
-	err = uc.metadataRepo.Update(ctx, image)
+	// 5. в единой транзакции - версия и текущий результат изображения
+	err = uc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.versionRepo.Create(ctx, version); err != nil {
+			return fmt.Errorf("uc.versionRepo.Create: %w", err)
+		}
+		if err := uc.metadataRepo.Update(ctx, image); err != nil {
+			return fmt.Errorf("uc.metadataRepo.Update: %w", err)
+		}
+
+		return nil
+	})
 	// если не удалось сохранить метаданные
 	if err != nil {
 		// удалим из S3
-		deleteErr := uc.imageRepo.Delete(ctx, processedKey)
-		if deleteErr != nil {
-			uc.logger.Error(deleteErr, "ImageUseCase - UploadProcessedImage - uc.imageRepo.Delete")
-		}
-		return fmt.Errorf("ImageUseCase - UploadProcessedImage - uc.metadataRepo.Update: %w", err)
+		uc.cleanupProcessed(ctx, processedKey, prefix, len(result.Files) > 0)
+		return fmt.Errorf("ImageUseCase - UploadProcessedImage - uc.transactor.WithinTransaction: %w", err)
 	}
 
 	return nil
@@ -165,23 +228,77 @@ func (uc *ImageUseCase) DeleteImage(ctx context.Context, id uuid.UUID) error {
 	}
 
 	// 3. удалим из S3
-	// оригинал
-	err = uc.imageRepo.Delete(ctx, image.OriginalKey)
-	if err != nil {
-		uc.logger.Warn("failed to delete key=%s, error=%v", image.OriginalKey, err)
+	// оригинал (у изображений, собранных из других, его нет)
+	if image.OriginalKey != "" {
+		err = uc.imageRepo.Delete(ctx, image.OriginalKey)
+		if err != nil {
+			uc.logger.Warn("failed to delete key=%s, error=%v", image.OriginalKey, err)
+		}
 	}
 
-	// обработанное
-	if image.ProcessedKey != nil {
+	// обработанное: до появления версий - единственный ключ, дальше - все версии под префиксом.
+	// ключ без версии удаляем всегда: после переобработки ProcessedKey указывает на версию,
+	// а processed/{id} под префикс версий не попадает
+	legacyKey := fmt.Sprintf("processed/%s", id)
+	err = uc.imageRepo.Delete(ctx, legacyKey)
+	if err != nil {
+		uc.logger.Warn("failed to delete key=%s, error=%v", legacyKey, err)
+	}
+	if image.ProcessedKey != nil && *image.ProcessedKey != legacyKey {
 		err = uc.imageRepo.Delete(ctx, *image.ProcessedKey)
 		if err != nil {
-			uc.logger.Warn("failed to delete key=%s, error=%v", image.OriginalKey, err)
+			uc.logger.Warn("failed to delete key=%s, error=%v", *image.ProcessedKey, err)
+		}
+	}
+	if image.CurrentVersionID != nil {
+		prefix := fmt.Sprintf("processed/%s/", id)
+		err = uc.imageRepo.DeletePrefix(ctx, prefix)
+		if err != nil {
+			uc.logger.Warn("failed to delete prefix=%s, error=%v", prefix, err)
+		}
+	}
+
+	// тайлы и прочие файлы результата (всех версий)
+	if image.CurrentVersionID != nil || image.Metadata.Tiles != nil || len(image.Metadata.Files) > 0 {
+		prefix := filesPrefix(id, nil)
+		err = uc.imageRepo.DeletePrefix(ctx, prefix)
+		if err != nil {
+			uc.logger.Warn("failed to delete prefix=%s, error=%v", prefix, err)
 		}
 	}
 
 	return nil
 }
 
+func (uc *ImageUseCase) GetImage(ctx context.Context, id uuid.UUID) (*entity.Image, error) {
+	image, err := uc.metadataRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - GetImage - uc.metadataRepo.GetByID: %w", err)
+	}
+
+	return image, nil
+}
+
+func (uc *ImageUseCase) ListImages(ctx context.Context, filter dto.ImageFilter) (dto.ImagePage, error) {
+	// запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+
+	images, err := uc.metadataRepo.List(ctx, filter)
+	if err != nil {
+		return dto.ImagePage{}, fmt.Errorf("ImageUseCase - ListImages - uc.metadataRepo.List: %w", err)
+	}
+
+	page := dto.ImagePage{Images: images}
+	if len(images) > limit {
+		page.Images = images[:limit]
+		last := page.Images[limit-1]
+		page.Next = &dto.ImageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
+
 func (uc *ImageUseCase) GetPendingEvents(ctx context.Context, maxRetries, limit int) ([]*entity.OutboxEvent, error) {
 	events, err := uc.outboxMetadataRepo.GetPendingEvents(ctx, maxRetries, limit)
 	if err != nil {
@@ -246,9 +363,33 @@ func (uc *ImageUseCase) GetProcessedKeyByID(ctx context.Context, id uuid.UUID) (
 }
 
 func (uc *ImageUseCase) MarkMaxRetriesAsFailed(ctx context.Context, maxRetries int) error {
-	err := uc.outboxMetadataRepo.MarkMaxRetriesAsFailed(ctx, maxRetries)
+	// событие и изображение помечаем вместе, иначе изображение навсегда останется pending
+	err := uc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		IDs, err := uc.outboxMetadataRepo.MarkMaxRetriesAsFailed(ctx, maxRetries)
+		if err != nil {
+			return fmt.Errorf("uc.outboxMetadataRepo.MarkMaxRetriesAsFailed: %w", err)
+		}
+		if len(IDs) == 0 {
+			return nil
+		}
+
+		if err := uc.metadataRepo.MarkAsFailedBatch(ctx, IDs, failureReasonNotQueued); err != nil {
+			return fmt.Errorf("uc.metadataRepo.MarkAsFailedBatch: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ImageUseCase - MarkMaxRetriesAsFailed - uc.transactor.WithinTransaction: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *ImageUseCase) MarkImageFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	err := uc.metadataRepo.MarkAsFailedBatch(ctx, uuid.UUIDs{id}, reason)
 	if err != nil {
-		return fmt.Errorf("ImageUseCase - MarkMaxRetriesAsFailed - uc.outboxMetadataRepo.MarkMaxRetriesAsFailed: %w", err)
+		return fmt.Errorf("ImageUseCase - MarkImageFailed - uc.metadataRepo.MarkAsFailedBatch: %w", err)
 	}
 
 	return nil
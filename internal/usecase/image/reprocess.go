@@ -0,0 +1,55 @@
+package image
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
+	"github.com/google/uuid"
+)
+
+// ReprocessImage ставит в очередь новую обработку уже загруженного оригинала.
+// Результат заменит текущий обработанный вариант; пока задача в очереди, изображение снова pending.
+func (uc *ImageUseCase) ReprocessImage(ctx context.Context, id uuid.UUID, operation dto.Operation) (*entity.Image, error) {
+	var image *entity.Image
+
+	err := uc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		// 1. оригинал должен быть: собранные из других изображений переобработать нечем
+		image, err = uc.metadataRepo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("uc.metadataRepo.GetByID: %w", err)
+		}
+		if image.OriginalKey == "" {
+			return errs.ErrNoOriginal
+		}
+
+		// 2. переводим в pending, если по изображению нет задачи в работе
+		if err := uc.metadataRepo.MarkAsPending(ctx, id, operation.Operation); err != nil {
+			return fmt.Errorf("uc.metadataRepo.MarkAsPending: %w", err)
+		}
+
+		// 3. записываем задачу в аутбокс
+		event, err := uc.createOutboxEvent(id, image.OriginalKey, image.ContentType, operation, nil)
+		if err != nil {
+			return fmt.Errorf("uc.createOutboxEvent: %w", err)
+		}
+		if err := uc.outboxMetadataRepo.Create(ctx, event); err != nil {
+			return fmt.Errorf("uc.outboxMetadataRepo.Create: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - ReprocessImage - uc.transactor.WithinTransaction: %w", err)
+	}
+
+	image.Status = entity.Pending
+	image.Operation = operation.Operation
+	image.FailureReason = nil
+
+	return image, nil
+}
@@ -0,0 +1,143 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/google/uuid"
+)
+
+const (
+	templateOperation = "template"
+
+	drawImage = "image"
+	drawText  = "text"
+)
+
+func (uc *ImageUseCase) CreateTemplate(ctx context.Context, template *entity.Template) error {
+	template.ID = uuid.New()
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+
+	if err := uc.templateRepo.Create(ctx, template); err != nil {
+		return fmt.Errorf("ImageUseCase - CreateTemplate - uc.templateRepo.Create: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *ImageUseCase) GetTemplate(ctx context.Context, id uuid.UUID) (*entity.Template, error) {
+	template, err := uc.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - GetTemplate - uc.templateRepo.GetByID: %w", err)
+	}
+
+	return template, nil
+}
+
+func (uc *ImageUseCase) ListTemplates(ctx context.Context) ([]*entity.Template, error) {
+	templates, err := uc.templateRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - ListTemplates - uc.templateRepo.List: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (uc *ImageUseCase) UpdateTemplate(ctx context.Context, template *entity.Template) error {
+	template.UpdatedAt = time.Now()
+
+	if err := uc.templateRepo.Update(ctx, template); err != nil {
+		return fmt.Errorf("ImageUseCase - UpdateTemplate - uc.templateRepo.Update: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *ImageUseCase) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	if err := uc.templateRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("ImageUseCase - DeleteTemplate - uc.templateRepo.Delete: %w", err)
+	}
+
+	return nil
+}
+
+// RenderTemplate создает изображение, которое воркер отрисует по шаблону.
+// images и texts уже сверены со слотами шаблона; текстовые слои без текста пропускаются.
+func (uc *ImageUseCase) RenderTemplate(
+	ctx context.Context,
+	template *entity.Template,
+	images map[string]uuid.UUID,
+	texts map[string]string,
+	contentType string,
+) (*entity.Image, error) {
+	render := dto.TemplateRender{
+		Width:      template.Width,
+		Height:     template.Height,
+		Background: template.Background,
+	}
+
+	// изображения слоев идут исходниками в порядке слоев
+	var IDs uuid.UUIDs
+	for _, layer := range template.Layers {
+		draw := dto.TemplateLayerDraw{
+			X:      layer.X,
+			Y:      layer.Y,
+			Width:  layer.Width,
+			Height: layer.Height,
+		}
+
+		switch layer.Type {
+		case entity.LayerBase, entity.LayerSlot:
+			id := images[layer.Name]
+			if layer.Type == entity.LayerBase {
+				id = *layer.ImageID
+			}
+
+			draw.Type = drawImage
+			draw.Source = len(IDs)
+			draw.Fit = layer.Fit
+			IDs = append(IDs, id)
+		case entity.LayerText:
+			text, ok := texts[layer.Name]
+			if !ok {
+				text = layer.Text
+			}
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+
+			draw.Type = drawText
+			draw.Text = text
+			draw.Font = layer.Font
+			draw.FontSize = layer.FontSize
+			draw.LineHeight = layer.LineHeight
+			draw.Color = layer.Color
+			draw.Align = layer.Align
+			draw.VAlign = layer.VAlign
+			draw.MaxLines = layer.MaxLines
+		default:
+			continue
+		}
+
+		render.Layers = append(render.Layers, draw)
+	}
+
+	operation := dto.Operation{
+		Operation: templateOperation,
+		Template:  &render,
+	}
+
+	name := fmt.Sprintf("%s.%s", template.Name, strings.TrimPrefix(contentType, "image/"))
+
+	image, err := uc.createFromSources(ctx, IDs, name, contentType, operation)
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - RenderTemplate - uc.createFromSources: %w", err)
+	}
+
+	return image, nil
+}
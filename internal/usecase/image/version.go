@@ -0,0 +1,52 @@
+package image
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
+	"github.com/google/uuid"
+)
+
+func (uc *ImageUseCase) ListVersions(ctx context.Context, id uuid.UUID) ([]*entity.ImageVersion, error) {
+	versions, err := uc.versionRepo.List(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - ListVersions - uc.versionRepo.List: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (uc *ImageUseCase) GetVersion(ctx context.Context, id uuid.UUID, number int) (*entity.ImageVersion, error) {
+	version, err := uc.versionRepo.GetByNumber(ctx, id, number)
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - GetVersion - uc.versionRepo.GetByNumber: %w", err)
+	}
+
+	return version, nil
+}
+
+// SetCurrentVersion возвращает изображению результат одной из прошлых обработок.
+func (uc *ImageUseCase) SetCurrentVersion(ctx context.Context, id uuid.UUID, number int) (*entity.ImageVersion, error) {
+	var version *entity.ImageVersion
+
+	err := uc.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		version, err = uc.versionRepo.GetByNumber(ctx, id, number)
+		if err != nil {
+			return fmt.Errorf("uc.versionRepo.GetByNumber: %w", err)
+		}
+
+		if err := uc.metadataRepo.SetCurrentVersion(ctx, version); err != nil {
+			return fmt.Errorf("uc.metadataRepo.SetCurrentVersion: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ImageUseCase - SetCurrentVersion - uc.transactor.WithinTransaction: %w", err)
+	}
+
+	return version, nil
+}
@@ -3,9 +3,12 @@ package imageprocessor
 import (
 	"context"
 	"fmt"
+	"image"
 
 	"github.com/andreyxaxa/Image-Processor/internal/dto"
+	"github.com/andreyxaxa/Image-Processor/internal/entity"
 	"github.com/andreyxaxa/Image-Processor/internal/infrastructure"
+	"github.com/andreyxaxa/Image-Processor/pkg/logger"
 	"github.com/andreyxaxa/Image-Processor/pkg/types/errs"
 )
 
@@ -13,20 +16,88 @@ const (
 	resize    = "resize"
 	watermark = "watermark"
 	thumbnail = "thumbnail"
+	collage   = "collage"
+	quantize  = "quantize"
+	enhance   = "auto_enhance"
+	trim      = "trim"
+	mark      = "invisible_watermark"
+	tiles     = "tiles"
+	sprite    = "sprite"
+	favicon   = "favicon"
+	keying    = "remove_background"
+	transform = "transform"
+	annotate  = "annotate"
+	template  = "template"
+
+	spriteManifestName = "sprite.json"
+
+	// промежуточный формат между шагами обработки - без потерь
+	intermediateContentType = "image/png"
+
+	svgContentType = "image/svg+xml"
 )
 
+// форматы, в которые может писать quantize
+var quantizeContentTypes = map[string]string{
+	"png": "image/png",
+	"gif": "image/gif",
+}
+
 type ImageProcessorUseCase struct {
 	p infrastructure.ImageProcessor
+
+	logger logger.Interface
 }
 
-func New(p infrastructure.ImageProcessor) *ImageProcessorUseCase {
-	return &ImageProcessorUseCase{p}
+func New(p infrastructure.ImageProcessor, l logger.Interface) *ImageProcessorUseCase {
+	return &ImageProcessorUseCase{
+		p:      p,
+		logger: l,
+	}
 }
 
-func (uc *ImageProcessorUseCase) Process(ctx context.Context, contentType string, task dto.Task) ([]byte, error) {
+func (uc *ImageProcessorUseCase) Process(ctx context.Context, contentType string, task dto.Task) (dto.Result, error) {
 	var result []byte
+	var metadata entity.Metadata
+	var files []dto.File
 	var err error
 
+	// векторный исходник сначала растеризуем, дальше работаем с png
+	if contentType == svgContentType && task.Data != nil {
+		opts := dto.RasterizeOptions{}
+		if task.Rasterize != nil {
+			opts = *task.Rasterize
+		}
+
+		task.Data, err = uc.p.Rasterize(ctx, intermediateContentType, task.Data, opts)
+		if err != nil {
+			return dto.Result{}, fmt.Errorf("ImageProcessorUseCase - Process - uc.p.Rasterize: %w", err)
+		}
+		contentType = intermediateContentType
+	}
+
+	// по умолчанию результат в формате оригинала
+	outContentType := contentType
+
+	// качество оцениваем по оригиналу, до предобработки. Это диагностика:
+	// ошибка оценки не мешает операции, качество просто остается неизвестным
+	if task.AssessQuality && task.Data != nil {
+		quality, err := uc.p.AssessQuality(ctx, task.Data)
+		if err != nil {
+			uc.logger.Error(err, "ImageProcessorUseCase - Process - uc.p.AssessQuality")
+		} else {
+			metadata.Quality = &quality
+		}
+	}
+
+	// автоулучшение как предобработка перед другой операцией
+	if task.Enhance != nil && task.Operation != enhance && task.Data != nil {
+		task.Data, err = uc.p.AutoEnhance(ctx, intermediateContentType, task.Data, *task.Enhance)
+		if err != nil {
+			return dto.Result{}, fmt.Errorf("ImageProcessorUseCase - Process - uc.p.AutoEnhance: %w", err)
+		}
+	}
+
 	switch task.Operation {
 	case resize:
 		result, err = uc.p.Resize(ctx, contentType, task.Data, *task.Width, *task.Height)
@@ -34,13 +105,120 @@ func (uc *ImageProcessorUseCase) Process(ctx context.Context, contentType string
 		result, err = uc.p.Watermark(ctx, contentType, task.Data, *task.Text)
 	case thumbnail:
 		result, err = uc.p.Thumbnail(ctx, contentType, task.Data)
+	case collage:
+		result, err = uc.p.Collage(ctx, contentType, task.Sources, *task.Collage)
+	case quantize:
+		ct, ok := quantizeContentTypes[task.Quantize.Format]
+		if !ok {
+			ct = quantizeContentTypes["png"]
+		}
+		outContentType = ct
+		result, err = uc.p.Quantize(ctx, outContentType, task.Data, *task.Quantize)
+	case enhance:
+		result, err = uc.p.AutoEnhance(ctx, contentType, task.Data, *task.Enhance)
+	case trim:
+		var rect image.Rectangle
+		result, rect, err = uc.p.Trim(ctx, contentType, task.Data, *task.Trim)
+		metadata.Trim = &entity.Rect{
+			X:      rect.Min.X,
+			Y:      rect.Min.Y,
+			Width:  rect.Dx(),
+			Height: rect.Dy(),
+		}
+	case mark:
+		result, err = uc.p.EmbedInvisibleWatermark(ctx, contentType, task.Data, task.Mark.Payload)
+	case tiles:
+		// результат - дескриптор, сами тайлы сохраняются рядом
+		var pyramid dto.TilePyramid
+		pyramid, err = uc.p.Tiles(ctx, task.Data, *task.Tiles)
+		result = pyramid.Descriptor
+		files = pyramid.Files
+		outContentType = pyramid.DescriptorContentType
+		metadata.Tiles = &entity.Tiles{
+			Layout:     task.Tiles.Layout,
+			Descriptor: pyramid.DescriptorName,
+			TileSize:   task.Tiles.TileSize,
+			Overlap:    task.Tiles.Overlap,
+			Format:     task.Tiles.Format,
+			Width:      pyramid.Width,
+			Height:     pyramid.Height,
+			Levels:     pyramid.Levels,
+		}
+	case sprite:
+		var manifest []byte
+		result, manifest, err = uc.p.Sprite(ctx, task.Sources, *task.Sprite)
+		outContentType = "image/png"
+		files = []dto.File{{Name: spriteManifestName, Data: manifest, ContentType: "application/json"}}
+		metadata.Files = []string{spriteManifestName}
+	case favicon:
+		result, files, err = uc.p.Favicon(ctx, task.Data)
+		outContentType = "image/x-icon"
+		for _, f := range files {
+			metadata.Files = append(metadata.Files, f.Name)
+		}
+	case keying:
+		// прозрачность есть только в png
+		outContentType = "image/png"
+		result, err = uc.p.RemoveBackground(ctx, task.Data, *task.Keying)
+	case transform:
+		result, err = uc.p.Transform(ctx, contentType, task.Data, *task.Transform)
+	case annotate:
+		result, err = uc.p.Annotate(ctx, contentType, task.Data, *task.Annotate)
+	case template:
+		result, err = uc.p.RenderTemplate(ctx, contentType, task.Sources, *task.Template)
 	default:
-		return nil, fmt.Errorf("ImageProcessorUseCase - Process: %w", errs.ErrUnknownOperation)
+		return dto.Result{}, fmt.Errorf("ImageProcessorUseCase - Process: %w", errs.ErrUnknownOperation)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("ImageProcessorUseCase - Process: %w", err)
+		return dto.Result{}, fmt.Errorf("ImageProcessorUseCase - Process: %w", err)
+	}
+
+	// EXIF/XMP пишем только в одиночный результат: тайлам, иконкам и листу спрайтов они не нужны
+	if len(files) == 0 {
+		var output dto.OutputMetadata
+		if task.Output != nil {
+			output = *task.Output
+		}
+
+		result, err = uc.p.WriteMetadata(ctx, result, output)
+		if err != nil {
+			return dto.Result{}, fmt.Errorf("ImageProcessorUseCase - Process - uc.p.WriteMetadata: %w", err)
+		}
+	}
+
+	return dto.Result{
+		Data:        result,
+		ContentType: outContentType,
+		Metadata:    metadata,
+		Files:       files,
+	}, nil
+}
+
+// TransformSize - размер результата transform для исходника width x height, без обработки.
+func (uc *ImageProcessorUseCase) TransformSize(ctx context.Context, width, height int, opts dto.TransformOptions) (int, int, error) {
+	w, h, err := uc.p.TransformSize(ctx, width, height, opts)
+	if err != nil {
+		return 0, 0, fmt.Errorf("ImageProcessorUseCase - TransformSize - uc.p.TransformSize: %w", err)
+	}
+
+	return w, h, nil
+}
+
+func (uc *ImageProcessorUseCase) Compare(ctx context.Context, a, b []byte, withDiff bool) (dto.Comparison, error) {
+	res, err := uc.p.Compare(ctx, a, b, withDiff)
+	if err != nil {
+		return dto.Comparison{}, fmt.Errorf("ImageProcessorUseCase - Compare - uc.p.Compare: %w", err)
+	}
+
+	return res, nil
+}
+
+func (uc *ImageProcessorUseCase) DetectWatermark(ctx context.Context, data []byte) (dto.WatermarkDetection, error) {
+	res, err := uc.p.DetectInvisibleWatermark(ctx, data)
+	if err != nil {
+		return dto.WatermarkDetection{}, fmt.Errorf("ImageProcessorUseCase - DetectWatermark - uc.p.DetectInvisibleWatermark: %w", err)
 	}
 
-	return result, nil
+	return res, nil
 }
@@ -0,0 +1,2 @@
+ALTER TABLE images
+    ADD COLUMN IF NOT EXISTS processed_content_type VARCHAR(100);
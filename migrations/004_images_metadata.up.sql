@@ -0,0 +1,2 @@
+ALTER TABLE images
+    ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS templates;
\ No newline at end of file
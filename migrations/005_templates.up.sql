@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS templates
+(
+    id         UUID PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    width      INTEGER NOT NULL,
+    height     INTEGER NOT NULL,
+    background VARCHAR(9) NOT NULL,
+    layers     JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_templates_created_at
+    ON templates(created_at DESC);
\ No newline at end of file
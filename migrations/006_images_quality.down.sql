@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_quality_grade_created_at;
+
+ALTER TABLE images
+    DROP COLUMN IF EXISTS quality_grade,
+    DROP COLUMN IF EXISTS sharpness;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE images
+    ADD COLUMN IF NOT EXISTS quality_grade VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS sharpness DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_quality_grade_created_at
+    ON images(quality_grade, created_at DESC)
+    WHERE quality_grade IS NOT NULL;
\ No newline at end of file
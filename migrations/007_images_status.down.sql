@@ -0,0 +1,6 @@
+-- значение 'failed' из перечисления image_status удалить нельзя, возвращаем такие изображения в очередь
+UPDATE images SET status = 'pending' WHERE status = 'failed';
+
+ALTER TABLE images
+    DROP COLUMN IF EXISTS failure_reason,
+    DROP COLUMN IF EXISTS operation;
\ No newline at end of file
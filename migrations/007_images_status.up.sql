@@ -0,0 +1,5 @@
+ALTER TYPE image_status ADD VALUE IF NOT EXISTS 'failed';
+
+ALTER TABLE images
+    ADD COLUMN IF NOT EXISTS operation      VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS failure_reason TEXT;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE images
+    DROP COLUMN IF EXISTS current_version_id;
+
+DROP TABLE IF EXISTS image_versions;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS image_versions
+(
+    id           UUID PRIMARY KEY,
+    image_id     UUID NOT NULL REFERENCES images(id) ON DELETE CASCADE,
+    number       INTEGER NOT NULL,
+    key          VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size         BIGINT NOT NULL,
+    operation    VARCHAR(32) NOT NULL,
+    spec         JSONB NOT NULL DEFAULT '{}',
+    metadata     JSONB NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (image_id, number)
+);
+
+ALTER TABLE images
+    ADD COLUMN IF NOT EXISTS current_version_id UUID REFERENCES image_versions(id) ON DELETE SET NULL;
\ No newline at end of file
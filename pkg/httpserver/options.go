@@ -36,3 +36,17 @@ func ShutdownTimeout(timeout time.Duration) Option {
 		s.shutdownTimeout = timeout
 	}
 }
+
+func BodyLimit(limit int) Option {
+	return func(s *Server) {
+		s.bodyLimit = limit
+	}
+}
+
+// StreamRequestBody отдает обработчику тело больше BodyLimit потоком вместо отказа.
+// Предел для остальных маршрутов тогда должен проверять middleware.
+func StreamRequestBody(stream bool) Option {
+	return func(s *Server) {
+		s.streamRequestBody = stream
+	}
+}
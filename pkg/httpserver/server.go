@@ -16,6 +16,7 @@ const (
 	_defaultReadTimeout     = 5 * time.Second
 	_defaultWriteTimeout    = 5 * time.Second
 	_defaultShutdownTimeout = 3 * time.Second
+	_defaultBodyLimit       = fiber.DefaultBodyLimit
 )
 
 type Server struct {
@@ -30,6 +31,9 @@ type Server struct {
 	readTimeout     time.Duration
 	writeTimeout    time.Duration
 	shutdownTimeout time.Duration
+	bodyLimit       int
+
+	streamRequestBody bool
 
 	logger logger.Interface
 }
@@ -47,6 +51,7 @@ func New(l logger.Interface, opts ...Option) *Server {
 		readTimeout:     _defaultReadTimeout,
 		writeTimeout:    _defaultWriteTimeout,
 		shutdownTimeout: _defaultShutdownTimeout,
+		bodyLimit:       _defaultBodyLimit,
 		logger:          l,
 	}
 
@@ -58,8 +63,13 @@ func New(l logger.Interface, opts ...Option) *Server {
 		Prefork:      s.prefork,
 		ReadTimeout:  s.readTimeout,
 		WriteTimeout: s.writeTimeout,
+		BodyLimit:    s.bodyLimit,
 		JSONDecoder:  json.Unmarshal,
 		JSONEncoder:  json.Marshal,
+
+		// при потоковом теле multipart читает обработчик: предразбор fasthttp читает форму целиком без предела
+		StreamRequestBody:            s.streamRequestBody,
+		DisablePreParseMultipartForm: s.streamRequestBody,
 	})
 
 	s.App = app
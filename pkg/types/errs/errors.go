@@ -5,4 +5,8 @@ import "errors"
 var (
 	ErrRecordNotFound   = errors.New("record not found")
 	ErrUnknownOperation = errors.New("unknown operation")
+	ErrImageBusy        = errors.New("image is already being processed")
+	ErrNoOriginal       = errors.New("image has no original")
+	ErrURLNotAllowed    = errors.New("url is not allowed")
+	ErrRemoteTooLarge   = errors.New("remote file is too large")
 )